@@ -0,0 +1,87 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/context"
+)
+
+// dualStackPod holds the dual-stack status fields, which predate the api
+// package and would otherwise be dropped when decoding into api.Pod.
+type dualStackPod struct {
+	Status struct {
+		PodIP  string `json:"podIP"`
+		PodIPs []struct {
+			IP string `json:"ip"`
+		} `json:"podIPs"`
+	} `json:"status"`
+}
+
+// dualStackService is the Service counterpart of dualStackPod.
+type dualStackService struct {
+	Spec struct {
+		ClusterIP  string   `json:"clusterIP"`
+		ClusterIPs []string `json:"clusterIPs"`
+	} `json:"spec"`
+}
+
+// PodIPs returns every IP assigned to the pod, primary address first. On
+// clusters without dual-stack support this is just status.podIP.
+func (c *Client) PodIPs(ctx context.Context, namespace, podName string) ([]string, error) {
+	apiResult, err := GetKubeResource(ctx, c.podURL(namespace, podName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var pod dualStackPod
+	if err := json.Unmarshal(apiResult, &pod); err != nil {
+		return nil, fmt.Errorf("failed to decode pod json: %v", err)
+	}
+
+	var ips []string
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, ip.IP)
+	}
+	if len(ips) == 0 && pod.Status.PodIP != "" {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	return ips, nil
+}
+
+// ServiceClusterIPs returns every cluster IP assigned to the service, primary
+// address first. On clusters without dual-stack support this is just
+// spec.clusterIP.
+func (c *Client) ServiceClusterIPs(ctx context.Context, namespace, serviceName string) ([]string, error) {
+	apiResult, err := GetKubeResource(ctx, c.serviceURL(namespace, serviceName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var service dualStackService
+	if err := json.Unmarshal(apiResult, &service); err != nil {
+		return nil, fmt.Errorf("failed to decode service json: %v", err)
+	}
+
+	ips := service.Spec.ClusterIPs
+	if len(ips) == 0 && service.Spec.ClusterIP != "" {
+		ips = []string{service.Spec.ClusterIP}
+	}
+	return ips, nil
+}
+
+// FilterIPFamily returns the addresses in ips that belong to the requested
+// family, preserving their order. Unparseable entries (such as a "None"
+// cluster IP) are skipped.
+func FilterIPFamily(ips []string, ipv6 bool) []string {
+	var filtered []string
+	for _, s := range ips {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			continue
+		}
+		if (ip.To4() == nil) == ipv6 {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
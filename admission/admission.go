@@ -0,0 +1,163 @@
+// Package admission contains an HTTP handler for building Kubernetes
+// admission webhooks.
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// maxRequestSize bounds the AdmissionReview bodies the handler reads. The
+// apiserver limits objects to a few megabytes.
+const maxRequestSize = 8 * 1024 * 1024
+
+// GroupVersionKind identifies the kind of the object under review.
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// GroupVersionResource identifies the resource the request was made to.
+type GroupVersionResource struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+}
+
+// UserInfo describes the user making the request.
+type UserInfo struct {
+	Username string   `json:"username,omitempty"`
+	UID      string   `json:"uid,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// Request is the request half of an admission.k8s.io/v1 AdmissionReview.
+type Request struct {
+	UID         string               `json:"uid"`
+	Kind        GroupVersionKind     `json:"kind"`
+	Resource    GroupVersionResource `json:"resource"`
+	SubResource string               `json:"subResource,omitempty"`
+	Name        string               `json:"name,omitempty"`
+	Namespace   string               `json:"namespace,omitempty"`
+	// Operation is CREATE, UPDATE, DELETE, or CONNECT.
+	Operation string          `json:"operation"`
+	UserInfo  UserInfo        `json:"userInfo"`
+	Object    json.RawMessage `json:"object,omitempty"`
+	OldObject json.RawMessage `json:"oldObject,omitempty"`
+	DryRun    *bool           `json:"dryRun,omitempty"`
+}
+
+// Status explains why a request was denied.
+type Status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Response is the response half of an AdmissionReview.
+type Response struct {
+	UID       string   `json:"uid"`
+	Allowed   bool     `json:"allowed"`
+	Result    *Status  `json:"status,omitempty"`
+	Patch     []byte   `json:"patch,omitempty"`
+	PatchType *string  `json:"patchType,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// Review is an admission.k8s.io/v1 AdmissionReview.
+type Review struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Request    *Request  `json:"request,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// MutateFunc returns the object under review with the desired changes made,
+// as any value that encodes to JSON, e.g. a modified *api.Pod or
+// map[string]interface{}. Returning an error denies the request.
+//
+// Fields the returned object omits are left unchanged, so decoding into a
+// type that lacks some of the object's fields is safe. To remove a field,
+// set it to null, which requires a map or a field without omitempty.
+type MutateFunc func(req *Request) (interface{}, error)
+
+// ValidateFunc returns an error to deny the request, whose message is
+// reported to the user.
+type ValidateFunc func(req *Request) error
+
+// Handler serves AdmissionReview requests. Validate, when set, runs first
+// and sees the request as it was received; Mutate, when set, only runs on
+// requests Validate allowed. Either may be nil.
+type Handler struct {
+	Mutate   MutateFunc
+	Validate ValidateFunc
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "admission reviews must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	var review Review
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("failed to decode admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := h.review(review.Request)
+	response.UID = review.Request.UID
+	resJSON, err := json.Marshal(Review{
+		APIVersion: review.APIVersion,
+		Kind:       "AdmissionReview",
+		Response:   response,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode admission review: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resJSON)
+}
+
+func (h *Handler) review(req *Request) *Response {
+	if h.Validate != nil {
+		if err := h.Validate(req); err != nil {
+			return deny(err)
+		}
+	}
+	if h.Mutate == nil || len(req.Object) == 0 {
+		return &Response{Allowed: true}
+	}
+
+	mutated, err := h.Mutate(req)
+	if err != nil {
+		return deny(err)
+	}
+	patch, err := CreatePatch(req.Object, mutated)
+	if err != nil {
+		return deny(fmt.Errorf("failed to create patch: %v", err))
+	}
+	if len(patch) == 0 {
+		return &Response{Allowed: true}
+	}
+	patchJSON, err := json.Marshal(patch)
+	if err != nil {
+		return deny(fmt.Errorf("failed to encode patch in json: %v", err))
+	}
+	patchType := "JSONPatch"
+	return &Response{Allowed: true, Patch: patchJSON, PatchType: &patchType}
+}
+
+func deny(err error) *Response {
+	return &Response{
+		Allowed: false,
+		Result:  &Status{Code: http.StatusForbidden, Message: err.Error()},
+	}
+}
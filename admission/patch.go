@@ -0,0 +1,97 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Operation is a single RFC 6902 JSON patch operation.
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON leaves out the value of remove operations only, so that
+// operations setting a value to null still carry it.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type operation Operation
+	return json.Marshal(operation(o))
+}
+
+// CreatePatch returns the JSON patch that turns the original JSON document
+// into mutated, which may be any value that encodes to JSON. Object fields
+// missing from mutated are kept, while fields set to null are removed.
+// Lists of different lengths are replaced whole.
+func CreatePatch(original []byte, mutated interface{}) ([]Operation, error) {
+	var from interface{}
+	if err := json.Unmarshal(original, &from); err != nil {
+		return nil, fmt.Errorf("failed to decode original object: %v", err)
+	}
+	mutatedJSON, err := json.Marshal(mutated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mutated object: %v", err)
+	}
+	var to interface{}
+	if err := json.Unmarshal(mutatedJSON, &to); err != nil {
+		return nil, fmt.Errorf("failed to decode mutated object: %v", err)
+	}
+	return diff(nil, "", from, to), nil
+}
+
+func diff(ops []Operation, path string, from, to interface{}) []Operation {
+	switch t := to.(type) {
+	case map[string]interface{}:
+		f, ok := from.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + escape(k)
+			fv, exists := f[k]
+			switch {
+			case t[k] == nil && exists:
+				ops = append(ops, Operation{Op: "remove", Path: p})
+			case t[k] == nil:
+			case !exists:
+				ops = append(ops, Operation{Op: "add", Path: p, Value: t[k]})
+			default:
+				ops = diff(ops, p, fv, t[k])
+			}
+		}
+		return ops
+	case []interface{}:
+		f, ok := from.([]interface{})
+		if !ok || len(f) != len(t) {
+			break
+		}
+		for i := range t {
+			ops = diff(ops, path+"/"+strconv.Itoa(i), f[i], t[i])
+		}
+		return ops
+	}
+	if reflect.DeepEqual(from, to) {
+		return ops
+	}
+	return append(ops, Operation{Op: "replace", Path: path, Value: to})
+}
+
+// escape escapes a key for use in a JSON pointer (RFC 6901).
+func escape(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,86 @@
+package kubeclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+)
+
+// ItemError is the failure of a bulk operation on one of its objects.
+type ItemError struct {
+	// Op is what failed, e.g. "delete" or "evict".
+	Op string
+	// Object is the object it failed on.
+	Object api.ObjectReference
+	Err    error
+}
+
+func (e *ItemError) Error() string {
+	name := e.Object.Name
+	if e.Object.Namespace != "" {
+		name = e.Object.Namespace + "/" + name
+	}
+	if e.Object.FieldPath != "" {
+		name += " " + e.Object.FieldPath
+	}
+	return fmt.Sprintf("%s %s %s: %v", e.Op, e.Object.Kind, name, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// AggregateError is returned by bulk operations, such as DrainNode and
+// CleanupCompleted, that carry on past the objects they fail on, with an
+// ItemError for each of them. errors.Is and errors.As match it if they
+// match any of its item errors, so, e.g., IsNotFound(err) reports whether
+// any of the objects was missing.
+type AggregateError struct {
+	Errors []*ItemError
+}
+
+func (e *AggregateError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *AggregateError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *AggregateError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add records that op failed on the object with err, unless err is nil.
+func (e *AggregateError) add(op string, object api.ObjectReference, err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, &ItemError{Op: op, Object: object, Err: err})
+	}
+}
+
+// err returns e, or nil if nothing failed.
+func (e *AggregateError) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
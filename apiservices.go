@@ -0,0 +1,252 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	apiregistrationGroup = "apiregistration.k8s.io"
+	apiServicesPath      = "/apis/" + apiregistrationGroup + "/v1/apiservices"
+	apiServicePath       = apiServicesPath + "/%s"
+)
+
+// APIService registers the server for an API group version with the
+// aggregation layer. Its name is "<version>.<group>".
+type APIService struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Spec           APIServiceSpec   `json:"spec,omitempty"`
+	Status         APIServiceStatus `json:"status,omitempty"`
+}
+
+type APIServiceSpec struct {
+	// Service is the service that serves the group version, or nil if the
+	// kube-apiserver serves it itself.
+	Service               *ServiceReference `json:"service,omitempty"`
+	Group                 string            `json:"group,omitempty"`
+	Version               string            `json:"version,omitempty"`
+	InsecureSkipTLSVerify bool              `json:"insecureSkipTLSVerify,omitempty"`
+	CABundle              []byte            `json:"caBundle,omitempty"`
+	GroupPriorityMinimum  int               `json:"groupPriorityMinimum"`
+	VersionPriority       int               `json:"versionPriority"`
+}
+
+type ServiceReference struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Port      *int   `json:"port,omitempty"`
+}
+
+type APIServiceStatus struct {
+	Conditions []APIServiceCondition `json:"conditions,omitempty"`
+}
+
+type APIServiceCondition struct {
+	Type    string              `json:"type"`
+	Status  api.ConditionStatus `json:"status"`
+	Reason  string              `json:"reason,omitempty"`
+	Message string              `json:"message,omitempty"`
+}
+
+// APIServiceUnavailableError is returned for requests to an aggregated API
+// group whose server is unavailable.
+type APIServiceUnavailableError struct {
+	// Name is the name of the APIService, e.g. "v1beta1.metrics.k8s.io".
+	Name string
+	// Reason and Message are taken from its Available condition, e.g.
+	// "FailedDiscoveryCheck" or "ServiceNotFound".
+	Reason  string
+	Message string
+	// Err is the *StatusError of the 503 response to the request, if the
+	// error explains one.
+	Err error
+}
+
+func (e *APIServiceUnavailableError) Error() string {
+	return fmt.Sprintf("APIService %s is unavailable: %s: %s", e.Name, e.Reason, e.Message)
+}
+
+func (e *APIServiceUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+type APIServiceResource struct {
+	Host  string
+	Label string
+}
+
+func (s *APIServiceResource) KubeResourcesURL() string {
+	return s.Host + apiServicesPath
+}
+
+// KubeResourceNamespace returns "" since APIServices are cluster-scoped.
+func (s *APIServiceResource) KubeResourceNamespace() string {
+	return ""
+}
+
+func (s *APIServiceResource) KubeResourceLabel() string {
+	return s.Label
+}
+
+func (c *Client) CreateAPIService(ctx context.Context, apiService *APIService) (*APIService, error) {
+	var apiServiceJSON bytes.Buffer
+	if err := json.NewEncoder(&apiServiceJSON).Encode(apiService); err != nil {
+		return nil, fmt.Errorf("failed to encode apiservice in json: %v", err)
+	}
+	apiResult, err := CreateKubeResource(ctx, &APIServiceResource{c.Host, ""}, apiServiceJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.apiServiceURL(apiService.Name), apiService)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var apiServiceResult APIService
+	if err := json.Unmarshal(apiResult, &apiServiceResult); err != nil {
+		return nil, fmt.Errorf("failed to decode apiservice resources: %v", err)
+	}
+	return &apiServiceResult, nil
+}
+
+// UpdateAPIService replaces the APIService. Its ResourceVersion guards
+// against overwriting concurrent changes.
+func (c *Client) UpdateAPIService(ctx context.Context, apiService *APIService) (*APIService, error) {
+	var apiServiceJSON bytes.Buffer
+	if err := json.NewEncoder(&apiServiceJSON).Encode(apiService); err != nil {
+		return nil, fmt.Errorf("failed to encode apiservice in json: %v", err)
+	}
+	url := c.apiServiceURL(apiService.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, apiServiceJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, apiService)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var apiServiceResult APIService
+	if err := json.Unmarshal(apiResult, &apiServiceResult); err != nil {
+		return nil, fmt.Errorf("failed to decode apiservice resources: %v", err)
+	}
+	return &apiServiceResult, nil
+}
+
+func (c *Client) DeleteAPIService(ctx context.Context, name string) error {
+	return DeleteKubeResource(ctx, c.apiServiceURL(name), c.Client)
+}
+
+func (c *Client) APIServiceList(ctx context.Context, label string, opts ...ListOptions) ([]APIService, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &APIServiceResource{c.Host, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var list struct {
+		Items []APIService `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode apiservice resources: %v", err)
+	}
+	return list.Items, nil
+}
+
+func (c *Client) GetAPIService(ctx context.Context, name string) (*APIService, error) {
+	return getAPIService(ctx, c.apiServiceURL(name), c.Client)
+}
+
+func getAPIService(ctx context.Context, url string, httpClient *http.Client) (*APIService, error) {
+	apiResult, err := GetKubeResource(ctx, url, httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var apiService APIService
+	if err := json.Unmarshal(apiResult, &apiService); err != nil {
+		return nil, fmt.Errorf("failed to decode apiservice json: %v", err)
+	}
+	return &apiService, nil
+}
+
+// APIServiceAvailable returns nil if the apiserver reports the APIService as
+// available, and an *APIServiceUnavailableError otherwise.
+func APIServiceAvailable(s *APIService) error {
+	for _, cond := range s.Status.Conditions {
+		if cond.Type != "Available" {
+			continue
+		}
+		if cond.Status == api.ConditionTrue {
+			return nil
+		}
+		return &APIServiceUnavailableError{Name: s.Name, Reason: cond.Reason, Message: cond.Message}
+	}
+	return &APIServiceUnavailableError{Name: s.Name, Reason: "Unknown", Message: "no Available condition reported yet"}
+}
+
+// CheckAPIService returns nil if the server for the group version is
+// available, and an *APIServiceUnavailableError if it is not.
+func (c *Client) CheckAPIService(ctx context.Context, group, version string) error {
+	apiService, err := c.GetAPIService(ctx, version+"."+group)
+	if err != nil {
+		return err
+	}
+	return APIServiceAvailable(apiService)
+}
+
+// AwaitAPIServiceAvailable polls the named APIService every interval until
+// the aggregation layer reports it available, e.g. once a newly deployed
+// metrics server passes its discovery check. Errors fetching it are retried,
+// since it may not have been created yet. If ctx is done first, the
+// returned error includes the last reason it was unavailable.
+func (c *Client) AwaitAPIServiceAvailable(ctx context.Context, name string, interval time.Duration) (*APIService, error) {
+	ticker := c.clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		apiService, err := c.GetAPIService(ctx, name)
+		if err == nil {
+			if err = APIServiceAvailable(apiService); err == nil {
+				return apiService, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("apiservice %s did not become available: %v (last error: %v)", name, ctx.Err(), err)
+		case <-ticker.C():
+		}
+	}
+}
+
+// unavailableError explains err, a 503 response to a request for rawurl, by
+// checking the APIService of the aggregated API group requested, if any.
+// The apiserver itself only reports "service unavailable". err is returned
+// unchanged if the APIService is available or cannot be fetched.
+func unavailableError(ctx context.Context, httpClient *http.Client, rawurl string, code int, err error) error {
+	if code != http.StatusServiceUnavailable {
+		return err
+	}
+	u, parseErr := url.Parse(rawurl)
+	if parseErr != nil {
+		return err
+	}
+	// Aggregated groups are served at /apis/<group>/<version>/...
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/apis/"), "/", 3)
+	if !strings.HasPrefix(u.Path, "/apis/") || len(parts) < 2 || parts[0] == apiregistrationGroup {
+		return err
+	}
+	apiServiceURL := u.Scheme + "://" + u.Host + fmt.Sprintf(apiServicePath, parts[1]+"."+parts[0])
+	apiService, getErr := getAPIService(ctx, apiServiceURL, httpClient)
+	if getErr != nil {
+		return err
+	}
+	if availErr := APIServiceAvailable(apiService); availErr != nil {
+		if unavailable, ok := availErr.(*APIServiceUnavailableError); ok {
+			unavailable.Err = err
+		}
+		return availErr
+	}
+	return err
+}
+
+func (c *Client) apiServiceURL(name string) string {
+	return c.Host + fmt.Sprintf(apiServicePath, name)
+}
@@ -0,0 +1,147 @@
+package kubeclient
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// ArchiveWriter writes exported objects, logs, and other artifacts into a
+// gzip compressed tar archive. Close must be called to flush the archive.
+type ArchiveWriter struct {
+	// Strip selects server-managed fields to leave out of the objects
+	// written, to keep snapshots small and their diffs meaningful.
+	// managedFields are always left out.
+	Strip StripOptions
+
+	gz  *gzip.Writer
+	tw  *tar.Writer
+	now time.Time
+}
+
+// NewArchiveWriter returns an ArchiveWriter writing a .tar.gz stream to w.
+func NewArchiveWriter(w io.Writer) *ArchiveWriter {
+	gz := gzip.NewWriter(w)
+	return &ArchiveWriter{
+		gz:  gz,
+		tw:  tar.NewWriter(gz),
+		now: time.Now(),
+	}
+}
+
+// WriteFile adds a file with the given contents to the archive.
+func (aw *ArchiveWriter) WriteFile(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: aw.now,
+	}
+	if err := aw.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write archive header for %s: %v", name, err)
+	}
+	if _, err := aw.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write archive file %s: %v", name, err)
+	}
+	return nil
+}
+
+// WriteObject adds obj to the archive as a YAML file rendered by Fprint, so
+// secret data is redacted, without the fields selected by aw.Strip.
+func (aw *ArchiveWriter) WriteObject(name string, obj interface{}) error {
+	strip := aw.Strip
+	strip.ManagedFields = true
+	var b bytes.Buffer
+	if err := fprint(&b, obj, strip); err != nil {
+		return err
+	}
+	return aw.WriteFile(name, b.Bytes())
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (aw *ArchiveWriter) Close() error {
+	if err := aw.tw.Close(); err != nil {
+		return err
+	}
+	return aw.gz.Close()
+}
+
+// ExportNamespace writes the pods, replication controllers, secrets, and
+// endpoints in namespace to aw as YAML files under <namespace>/<kind>/, along
+// with the log of every container under <namespace>/logs/<pod>/. If ctx is
+// done first, it returns an *ErrPartial and aw holds what was exported so
+// far.
+func (c *Client) ExportNamespace(ctx context.Context, namespace string, aw *ArchiveWriter) error {
+	dir := func(kind, name string) string {
+		return path.Join(namespace, kind, name+".yaml")
+	}
+	// failed returns err, or an *ErrPartial listing missing if ctx is done.
+	failed := func(err error, missing ...string) error {
+		if ctx.Err() != nil {
+			return &ErrPartial{Missing: missing, Err: ctx.Err()}
+		}
+		return err
+	}
+
+	pods, err := c.PodList(ctx, namespace, "")
+	if err != nil {
+		return failed(err, "pods", "logs", "replicationcontrollers", "secrets", "endpoints")
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if err := aw.WriteObject(dir("pods", pod.Name), pod); err != nil {
+			return err
+		}
+		logs, err := c.PodLogAllContainers(ctx, namespace, pod.Name)
+		if err != nil && ctx.Err() != nil {
+			return failed(err, "logs", "replicationcontrollers", "secrets", "endpoints")
+		}
+		for _, container := range pod.Spec.Containers {
+			log, ok := logs[container.Name]
+			if !ok {
+				// Containers that have not started have no log yet.
+				log = fmt.Sprintf("failed to retrieve log: %v\n", err)
+			}
+			if err := aw.WriteFile(path.Join(namespace, "logs", pod.Name, container.Name+".log"), []byte(log)); err != nil {
+				return err
+			}
+		}
+	}
+
+	rcs, err := c.ReplicationControllerList(ctx, namespace, "")
+	if err != nil {
+		return failed(err, "replicationcontrollers", "secrets", "endpoints")
+	}
+	for i := range rcs {
+		if err := aw.WriteObject(dir("replicationcontrollers", rcs[i].Name), &rcs[i]); err != nil {
+			return err
+		}
+	}
+
+	secrets, err := c.SecretList(ctx, namespace, "")
+	if err != nil {
+		return failed(err, "secrets", "endpoints")
+	}
+	for i := range secrets {
+		if err := aw.WriteObject(dir("secrets", secrets[i].Name), &secrets[i]); err != nil {
+			return err
+		}
+	}
+
+	endpoints, err := c.EndpointsList(ctx, namespace, "")
+	if err != nil {
+		return failed(err, "endpoints")
+	}
+	for i := range endpoints {
+		if err := aw.WriteObject(dir("endpoints", endpoints[i].Name), &endpoints[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
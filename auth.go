@@ -0,0 +1,343 @@
+package kubeclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// execCredentialAPIVersion is the ExecCredential version exec plugins are
+// asked for when their config does not name one.
+const execCredentialAPIVersion = "client.authentication.k8s.io/v1"
+
+// AuthProvider authenticates the client's requests, for schemes beyond the
+// built-in bearer token, basic auth, client certificate, and exec plugin
+// providers.
+type AuthProvider interface {
+	// Authenticate adds credentials to req, a copy of the request being
+	// sent whose headers it may modify.
+	Authenticate(req *http.Request) error
+	// Refresh is called when the apiserver rejects a request with 401
+	// Unauthorized, to drop any cached credentials. It reports whether
+	// fresh credentials may be available, in which case the request is
+	// authenticated and sent again, once.
+	Refresh() bool
+}
+
+// CertificateProvider is implemented by AuthProviders that authenticate with
+// a client certificate. The certificate is requested for each new
+// connection; established connections keep the one they started with.
+type CertificateProvider interface {
+	GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+}
+
+// UseAuthProvider authenticates every request made through c with p. If p
+// is a CertificateProvider, it also supplies the client certificate for
+// new connections, which requires c to use an *http.Transport.
+func (c *Client) UseAuthProvider(p AuthProvider) error {
+	if cp, ok := p.(CertificateProvider); ok {
+		tr, err := c.transport()
+		if err != nil {
+			return err
+		}
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		}
+		tr.TLSClientConfig.GetClientCertificate = cp.GetClientCertificate
+	}
+	c.Client.Transport = &authTransport{provider: p, rt: c.Client.Transport}
+	return nil
+}
+
+// authTransport authenticates requests with an AuthProvider, retrying once
+// with refreshed credentials on 401 Unauthorized.
+type authTransport struct {
+	provider AuthProvider
+	rt       http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.send(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return res, nil
+	}
+	if !t.provider.Refresh() {
+		return res, nil
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	if req.Body != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r := new(http.Request)
+		*r = *req
+		r.Body = body
+		req = r
+	}
+	return t.send(req)
+}
+
+// send authenticates a copy of req and sends it.
+func (t *authTransport) send(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the request they are given.
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	if err := t.provider.Authenticate(r); err != nil {
+		return nil, err
+	}
+	return t.rt.RoundTrip(r)
+}
+
+func (t *authTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
+
+// BearerTokenAuth authenticates with a fixed bearer token.
+func BearerTokenAuth(token string) AuthProvider {
+	return bearerTokenAuth{token: func() (string, error) { return token, nil }}
+}
+
+// TokenFileAuth authenticates with the bearer token in the file at path,
+// re-reading it when it changes, as projected service account tokens are
+// rotated.
+func TokenFileAuth(path string) AuthProvider {
+	f := &tokenFile{path: path}
+	return bearerTokenAuth{token: f.get, refresh: f.expire}
+}
+
+type bearerTokenAuth struct {
+	token   func() (string, error)
+	refresh func() bool
+}
+
+func (a bearerTokenAuth) Authenticate(req *http.Request) error {
+	token, err := a.token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a bearerTokenAuth) Refresh() bool {
+	return a.refresh != nil && a.refresh()
+}
+
+// BasicAuth authenticates with a username and password, which only old
+// clusters accept.
+func BasicAuth(username, password string) AuthProvider {
+	return basicAuth{username, password}
+}
+
+type basicAuth struct {
+	username, password string
+}
+
+func (a basicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+func (basicAuth) Refresh() bool {
+	return false
+}
+
+// ClientCertAuth authenticates with a fixed client certificate.
+func ClientCertAuth(cert tls.Certificate) AuthProvider {
+	return clientCertAuth{get: func() (*tls.Certificate, error) { return &cert, nil }}
+}
+
+// ClientCertFilesAuth authenticates with the client certificate in certFile
+// and keyFile, loading it again when either file changes.
+func ClientCertFilesAuth(certFile, keyFile string) (AuthProvider, error) {
+	files, err := newKeyPairFiles(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return clientCertAuth{get: files.get}, nil
+}
+
+type clientCertAuth struct {
+	get func() (*tls.Certificate, error)
+}
+
+func (clientCertAuth) Authenticate(*http.Request) error {
+	return nil
+}
+
+func (clientCertAuth) Refresh() bool {
+	return false
+}
+
+func (a clientCertAuth) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return a.get()
+}
+
+// ExecAuthConfig configures an exec credential plugin, a command that
+// prints an ExecCredential holding a token or client certificate, as
+// cloud providers' CLIs do.
+type ExecAuthConfig struct {
+	Command string
+	Args    []string
+	// Env is added to the program's environment.
+	Env map[string]string
+	// APIVersion is the ExecCredential version the plugin is asked for,
+	// client.authentication.k8s.io/v1 if empty.
+	APIVersion string
+	// Timeout bounds each run of the plugin, which is killed if it takes
+	// longer. It defaults to a minute. Requests wait for the plugin while
+	// it runs, so a hung plugin must not be left to block them.
+	Timeout time.Duration
+}
+
+// defaultExecTimeout is the default ExecAuthConfig.Timeout.
+const defaultExecTimeout = time.Minute
+
+// ExecAuth authenticates with credentials from an exec plugin. The plugin
+// is run when credentials are first needed, and again once they expire or
+// the apiserver rejects them. A plugin issuing client certificates must
+// be used with UseAuthProvider, so new connections present them.
+func ExecAuth(config ExecAuthConfig) AuthProvider {
+	return &execAuth{config: config}
+}
+
+type execAuth struct {
+	config ExecAuthConfig
+
+	mu      sync.Mutex
+	cred    *execCredentialStatus
+	expires time.Time
+}
+
+// execCredentialStatus is the status of an ExecCredential.
+type execCredentialStatus struct {
+	Token                 string `json:"token"`
+	ExpirationTimestamp   string `json:"expirationTimestamp"`
+	ClientCertificateData string `json:"clientCertificateData"`
+	ClientKeyData         string `json:"clientKeyData"`
+
+	cert *tls.Certificate
+}
+
+func (a *execAuth) Authenticate(req *http.Request) error {
+	cred, err := a.credential()
+	if err != nil {
+		return err
+	}
+	if cred.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cred.Token)
+	}
+	return nil
+}
+
+func (a *execAuth) Refresh() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cred = nil
+	return true
+}
+
+func (a *execAuth) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cred, err := a.credential()
+	if err != nil {
+		return nil, err
+	}
+	if cred.cert == nil {
+		// No certificate; the handshake continues without one.
+		return &tls.Certificate{}, nil
+	}
+	return cred.cert, nil
+}
+
+// credential returns the cached credential, running the plugin if there is
+// none or it has expired.
+func (a *execAuth) credential() (*execCredentialStatus, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cred != nil && (a.expires.IsZero() || time.Now().Before(a.expires)) {
+		return a.cred, nil
+	}
+
+	apiVersion := a.config.APIVersion
+	if apiVersion == "" {
+		apiVersion = execCredentialAPIVersion
+	}
+	info, err := json.Marshal(map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]interface{}{"interactive": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+	timeout := a.config.Timeout
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, a.config.Command, a.config.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for k, v := range a.config.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("exec credential plugin %s did not finish within %v", a.config.Command, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("exec credential plugin %s failed: %v: %s", a.config.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var credential struct {
+		APIVersion string                `json:"apiVersion"`
+		Kind       string                `json:"kind"`
+		Status     *execCredentialStatus `json:"status"`
+	}
+	if err := json.Unmarshal(out, &credential); err != nil {
+		return nil, fmt.Errorf("failed to decode exec credential json: %v", err)
+	}
+	cred := credential.Status
+	if credential.Kind != "ExecCredential" || cred == nil {
+		return nil, fmt.Errorf("exec credential plugin %s returned no credential", a.config.Command)
+	}
+	if cred.ClientCertificateData != "" || cred.ClientKeyData != "" {
+		cert, err := tls.X509KeyPair([]byte(cred.ClientCertificateData), []byte(cred.ClientKeyData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exec credential client certificate: %v", err)
+		}
+		cred.cert = &cert
+	}
+	a.expires = time.Time{}
+	if cred.ExpirationTimestamp != "" {
+		expires, err := time.Parse(time.RFC3339, cred.ExpirationTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exec credential expirationTimestamp %q: %v", cred.ExpirationTimestamp, err)
+		}
+		a.expires = expires
+	}
+	a.cred = cred
+	return cred, nil
+}
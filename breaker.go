@@ -0,0 +1,135 @@
+package kubeclient
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for requests rejected by an open CircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit breaker open: apiserver is failing, request not sent")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects every request with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe request through; its outcome
+	// decides whether the breaker closes or opens again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker fails requests fast once the apiserver has failed Threshold
+// times in a row. After Cooldown it half-opens and lets one probe through:
+// if the probe succeeds the breaker closes, otherwise it stays open for
+// another Cooldown. Transport errors and 5xx responses count as failures.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+	// Clock, if set, replaces the real clock in measuring Cooldown.
+	Clock Clock
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// UseCircuitBreaker makes every request made through c pass through cb.
+func (c *Client) UseCircuitBreaker(cb *CircuitBreaker) {
+	c.Client.Transport = &breakerTransport{cb: cb, rt: c.Client.Transport}
+}
+
+// State returns the current state of the breaker.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.Cooldown {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) now() time.Time {
+	if cb.Clock == nil {
+		return time.Now()
+	}
+	return cb.Clock.Now()
+}
+
+// allow reports whether a request may be sent now.
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case CircuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.Cooldown {
+			return false
+		}
+		cb.state = CircuitHalfOpen
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+	}
+	return true
+}
+
+// record updates the breaker with the outcome of an allowed request.
+func (cb *CircuitBreaker) record(failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+	if !failed {
+		cb.state = CircuitClosed
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.Threshold {
+		cb.state = CircuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+type breakerTransport struct {
+	cb *CircuitBreaker
+	rt http.RoundTripper
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.cb.allow() {
+		return nil, ErrCircuitOpen
+	}
+	res, err := t.rt.RoundTrip(req)
+	t.cb.record(err != nil || res.StatusCode >= http.StatusInternalServerError)
+	return res, err
+}
+
+func (t *breakerTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
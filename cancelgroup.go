@@ -0,0 +1,139 @@
+package kubeclient
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+type cancelGroupKey struct{}
+
+// WithCancelGroup returns a copy of ctx that tags every request made with it
+// as part of the named group, so that they can all be cancelled together
+// with Client.CancelGroup, e.g. every call made for a single deploy.
+func WithCancelGroup(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, cancelGroupKey{}, name)
+}
+
+func cancelGroupFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(cancelGroupKey{}).(string)
+	return name
+}
+
+// CancelGroup cancels every in-flight request, including open watches and
+// log streams, made with a context from WithCancelGroup(ctx, name). Later
+// requests in the group are unaffected. It returns the number of requests
+// cancelled.
+func (c *Client) CancelGroup(name string) int {
+	if c.groups == nil {
+		return 0
+	}
+	return c.groups.cancel(name)
+}
+
+// UseCancelGroups enables CancelGroup on a client that was not built by
+// this package's constructors, which enable it already.
+func (c *Client) UseCancelGroups() {
+	if c.groups != nil {
+		return
+	}
+	c.groups = newCancelGroups()
+	c.Client.Transport = &cancelGroupTransport{groups: c.groups, rt: c.Client.Transport}
+}
+
+// cancelGroups tracks the cancel funcs of in-flight requests by group.
+type cancelGroups struct {
+	mu     sync.Mutex
+	nextID int
+	groups map[string]map[int]context.CancelFunc
+}
+
+func newCancelGroups() *cancelGroups {
+	return &cancelGroups{groups: make(map[string]map[int]context.CancelFunc)}
+}
+
+func (g *cancelGroups) add(name string, cancel context.CancelFunc) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextID++
+	if g.groups[name] == nil {
+		g.groups[name] = make(map[int]context.CancelFunc)
+	}
+	g.groups[name][g.nextID] = cancel
+	return g.nextID
+}
+
+func (g *cancelGroups) remove(name string, id int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.groups[name], id)
+	if len(g.groups[name]) == 0 {
+		delete(g.groups, name)
+	}
+}
+
+func (g *cancelGroups) cancel(name string) int {
+	g.mu.Lock()
+	requests := g.groups[name]
+	delete(g.groups, name)
+	g.mu.Unlock()
+	for _, cancel := range requests {
+		cancel()
+	}
+	return len(requests)
+}
+
+// cancelGroupTransport gives each request tagged with a cancel group a
+// context that CancelGroup can cancel, until its response body is closed.
+type cancelGroupTransport struct {
+	groups *cancelGroups
+	rt     http.RoundTripper
+}
+
+func (t *cancelGroupTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := cancelGroupFromContext(req.Context())
+	if name == "" {
+		return t.rt.RoundTrip(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	id := t.groups.add(name, cancel)
+	done := func() {
+		t.groups.remove(name, id)
+		cancel()
+	}
+	res, err := t.rt.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		done()
+		return nil, err
+	}
+	body := &doneOnClose{ReadCloser: res.Body, done: done}
+	if w, ok := res.Body.(io.Writer); ok {
+		// Keep upgraded connections writable.
+		res.Body = struct {
+			*doneOnClose
+			io.Writer
+		}{body, w}
+	} else {
+		res.Body = body
+	}
+	return res, nil
+}
+
+func (t *cancelGroupTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
+
+// doneOnClose calls done once its body is closed.
+type doneOnClose struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *doneOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
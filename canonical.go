@@ -0,0 +1,115 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// serverMetadataFields are the metadata fields the apiserver sets, which do
+// not belong in a last-applied configuration.
+var serverMetadataFields = []string{
+	"creationTimestamp", "deletionGracePeriodSeconds", "deletionTimestamp",
+	"generation", "managedFields", "resourceVersion", "selfLink", "uid",
+}
+
+// CanonicalJSON encodes obj as JSON with object keys sorted and with null
+// fields and empty object and list fields removed, so that identical
+// objects always encode to identical bytes, however they were built.
+// Numbers are kept exactly as obj encodes them, and list elements are never
+// removed.
+func CanonicalJSON(obj interface{}) ([]byte, error) {
+	fields, err := canonicalFields(obj)
+	if err != nil {
+		return nil, err
+	}
+	return encodeCanonical(fields)
+}
+
+// LastAppliedConfiguration returns the canonical JSON of obj without its
+// status and server populated metadata, as recorded in the
+// kubectl.kubernetes.io/last-applied-configuration annotation.
+func LastAppliedConfiguration(obj interface{}) (string, error) {
+	fields, err := canonicalFields(obj)
+	if err != nil {
+		return "", err
+	}
+	delete(fields, "status")
+	if meta, ok := fields["metadata"].(map[string]interface{}); ok {
+		for _, f := range serverMetadataFields {
+			delete(meta, f)
+		}
+		if annotations, ok := meta["annotations"].(map[string]interface{}); ok {
+			delete(annotations, lastAppliedAnnotation)
+		}
+		if prune(meta) == nil {
+			delete(fields, "metadata")
+		}
+	}
+	b, err := encodeCanonical(fields)
+	return string(b), err
+}
+
+// canonicalFields returns obj as pruned generic JSON fields, decoding
+// numbers as json.Number so they survive unchanged.
+func canonicalFields(obj interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode object in json: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to decode object json: %v", err)
+	}
+	pruned, _ := prune(fields).(map[string]interface{})
+	if pruned == nil {
+		pruned = make(map[string]interface{})
+	}
+	return pruned, nil
+}
+
+// prune removes the null fields and the empty object and list fields of
+// objects in v, recursively, and returns the result, which is nil if v
+// itself is null or empty. Scalars are kept even when false, zero, or "",
+// since an explicit replicas: 0 or automountServiceAccountToken: false
+// means something other than leaving the field unset.
+func prune(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if field = prune(field); field == nil {
+				delete(v, k)
+			} else {
+				v[k] = field
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		// Empty elements are kept so that list positions are stable.
+		for i, elem := range v {
+			if pruned := prune(elem); pruned != nil {
+				v[i] = pruned
+			}
+		}
+	}
+	return v
+}
+
+// encodeCanonical encodes v without HTML escaping or a trailing newline.
+// encoding/json writes map keys in sorted order.
+func encodeCanonical(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode object in json: %v", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,77 @@
+package kubeclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// certRecheckInterval is how often client certificate files are checked for
+// rotation.
+const certRecheckInterval = time.Minute
+
+// keyPairFiles caches a client certificate loaded from a certificate and key
+// file, loading it again when either file's modification time changes, so
+// that long-running clients pick up rotated certificates. New connections
+// use the new certificate; established ones keep the one they started with.
+type keyPairFiles struct {
+	certFile, keyFile string
+
+	mu              sync.Mutex
+	cert            *tls.Certificate
+	certMod, keyMod time.Time
+	checked         time.Time
+}
+
+// newKeyPairFiles loads the key pair in certFile and keyFile.
+func newKeyPairFiles(certFile, keyFile string) (*keyPairFiles, error) {
+	f := &keyPairFiles{certFile: certFile, keyFile: keyFile}
+	if _, err := f.get(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *keyPairFiles) get() (*tls.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cert != nil && time.Since(f.checked) < certRecheckInterval {
+		return f.cert, nil
+	}
+	certInfo, certErr := os.Stat(f.certFile)
+	keyInfo, keyErr := os.Stat(f.keyFile)
+	if certErr != nil || keyErr != nil {
+		if f.cert != nil {
+			// Keep using the last certificate; the files may be
+			// mid-rotation.
+			return f.cert, nil
+		}
+		if certErr != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %v", certErr)
+		}
+		return nil, fmt.Errorf("failed to read client key: %v", keyErr)
+	}
+	f.checked = time.Now()
+	if f.cert != nil && certInfo.ModTime().Equal(f.certMod) && keyInfo.ModTime().Equal(f.keyMod) {
+		return f.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+	if err != nil {
+		if f.cert != nil {
+			// One file may have been replaced before the other; try
+			// again at the next check.
+			return f.cert, nil
+		}
+		return nil, fmt.Errorf("failed to load client certificate: %v", err)
+	}
+	f.cert = &cert
+	f.certMod, f.keyMod = certInfo.ModTime(), keyInfo.ModTime()
+	return f.cert, nil
+}
+
+// getClientCertificate is a tls.Config GetClientCertificate func.
+func (f *keyPairFiles) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return f.get()
+}
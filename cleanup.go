@@ -0,0 +1,156 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	// cleanupPageSize is the number of objects CleanupCompleted lists at a
+	// time.
+	cleanupPageSize = 500
+	// cleanupDeleteInterval paces the deletes CleanupCompleted issues.
+	cleanupDeleteInterval = 50 * time.Millisecond
+)
+
+// CleanupCompleted deletes the completed objects of the given kinds in
+// namespace that finished more than olderThan ago, for clusters without a
+// TTL controller. Supported kinds are "pods" (Succeeded or Failed pods) and
+// "jobs" (Complete or Failed jobs, along with their pods). If no kinds are
+// given, all supported kinds are cleaned up.
+// Objects that fail to be deleted are skipped and reported together in an
+// AggregateError at the end; failing to list them stops the cleanup.
+// Objects are listed a page at a time and deleted at a limited rate with
+// low priority, so a large cleanup does not crowd out other requests. It
+// returns the number of objects deleted.
+func (c *Client) CleanupCompleted(ctx context.Context, namespace string, olderThan time.Duration, kinds ...string) (int, error) {
+	if len(kinds) == 0 {
+		kinds = []string{"jobs", "pods"}
+	}
+	ctx = WithPriority(ctx, PriorityLow)
+	cutoff := c.clock().Now().Add(-olderThan)
+	pace := c.clock().NewTicker(cleanupDeleteInterval)
+	defer pace.Stop()
+
+	deleted := 0
+	var failed AggregateError
+	for _, kind := range kinds {
+		var n int
+		var err error
+		switch kind {
+		case "pods":
+			n, err = c.cleanupCompletedPods(ctx, namespace, cutoff, pace.C(), &failed)
+		case "jobs":
+			n, err = c.cleanupCompletedJobs(ctx, namespace, cutoff, pace.C(), &failed)
+		default:
+			return deleted, fmt.Errorf("cleanup of %q is not supported", kind)
+		}
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, failed.err()
+}
+
+func (c *Client) cleanupCompletedPods(ctx context.Context, namespace string, cutoff time.Time, pace <-chan time.Time, failed *AggregateError) (int, error) {
+	// Deleting while paging is safe: the continue token pins the list to
+	// a consistent snapshot.
+	deleted := 0
+	continueToken := ""
+	for {
+		apiResult, next, err := listKubeResourcesPage(ctx, &PodResource{c.Host, namespace, ""}, cleanupPageSize, continueToken, c.Client)
+		if err != nil {
+			return deleted, fmt.Errorf("Resource List failed: %w", err)
+		}
+		var podList api.PodList
+		if err := json.Unmarshal(apiResult, &podList); err != nil {
+			return deleted, fmt.Errorf("failed to decode pod resources: %v", err)
+		}
+
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if pod.Status.Phase != api.PodSucceeded && pod.Status.Phase != api.PodFailed {
+				continue
+			}
+			if !podFinishedAt(pod).Before(cutoff) {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return deleted, ctx.Err()
+			case <-pace:
+			}
+			if err := c.DeletePod(ctx, namespace, pod.Name); err != nil {
+				if !IsNotFound(err) {
+					failed.add("delete", api.ObjectReference{Kind: "Pod", Namespace: namespace, Name: pod.Name}, err)
+				}
+				continue
+			}
+			deleted++
+		}
+
+		if next == "" {
+			return deleted, nil
+		}
+		continueToken = next
+	}
+}
+
+func (c *Client) cleanupCompletedJobs(ctx context.Context, namespace string, cutoff time.Time, pace <-chan time.Time, failed *AggregateError) (int, error) {
+	deleted := 0
+	continueToken := ""
+	for {
+		apiResult, next, err := listKubeResourcesPage(ctx, &JobResource{c.Host, namespace, ""}, cleanupPageSize, continueToken, c.Client)
+		if err != nil {
+			return deleted, fmt.Errorf("Resource List failed: %w", err)
+		}
+		var jobList struct {
+			Items []Job `json:"items"`
+		}
+		if err := json.Unmarshal(apiResult, &jobList); err != nil {
+			return deleted, fmt.Errorf("failed to decode job resources: %v", err)
+		}
+
+		for i := range jobList.Items {
+			job := &jobList.Items[i]
+			cond := JobFinished(job)
+			if cond == nil || !cond.LastTransitionTime.Before(cutoff) {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return deleted, ctx.Err()
+			case <-pace:
+			}
+			if err := c.DeleteJob(ctx, namespace, job.Name); err != nil {
+				if !IsNotFound(err) {
+					failed.add("delete", api.ObjectReference{Kind: "Job", Namespace: namespace, Name: job.Name}, err)
+				}
+				continue
+			}
+			deleted++
+		}
+
+		if next == "" {
+			return deleted, nil
+		}
+		continueToken = next
+	}
+}
+
+// podFinishedAt returns when the last container in a completed pod
+// terminated, falling back to the pod's creation time.
+func podFinishedAt(pod *api.Pod) time.Time {
+	finished := pod.CreationTimestamp.UTC()
+	for _, status := range pod.Status.ContainerStatuses {
+		if t := status.State.Terminated; t != nil && t.FinishedAt.After(finished) {
+			finished = t.FinishedAt.UTC()
+		}
+	}
+	return finished
+}
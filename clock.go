@@ -0,0 +1,176 @@
+package kubeclient
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Clock is the source of time for the client's timeouts, backoffs, and
+// polling waits. Tests can set Client.Clock to a FakeClock to advance time
+// deterministically rather than sleeping.
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a timer that fires once, after d.
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a ticker that fires every d.
+	NewTicker(d time.Duration) Timer
+}
+
+// Timer is a timer or ticker created by a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// clock returns c.Clock, or the real clock if it is not set.
+func (c *Client) clock() Clock {
+	if c.Clock == nil {
+		return realClock{}
+	}
+	return c.Clock
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Timer {
+	return &realTimer{ticker: time.NewTicker(d)}
+}
+
+type realTimer struct {
+	timer  *time.Timer
+	ticker *time.Ticker
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	if t.ticker != nil {
+		return t.ticker.C
+	}
+	return t.timer.C
+}
+
+func (t *realTimer) Stop() {
+	if t.ticker != nil {
+		t.ticker.Stop()
+		return
+	}
+	t.timer.Stop()
+}
+
+// withTimeout is context.WithTimeout measured by clock.
+func withTimeout(ctx context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	t := clock.NewTimer(d)
+	go func() {
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+		case <-t.C():
+			cancel()
+		}
+	}()
+	return ctx, cancel
+}
+
+// FakeClock is a Clock whose time only moves when Advance is called.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *FakeClock) NewTimer(d time.Duration) Timer {
+	return f.add(d, 0)
+}
+
+func (f *FakeClock) NewTicker(d time.Duration) Timer {
+	return f.add(d, d)
+}
+
+func (f *FakeClock) add(d, period time.Duration) *fakeTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), at: f.now.Add(d), period: period}
+	f.timers = append(f.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers that
+// come due. Like the real ones, tickers drop ticks nobody received.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		for !t.at.After(f.now) {
+			select {
+			case t.c <- t.at:
+			default:
+			}
+			if t.period <= 0 {
+				break
+			}
+			t.at = t.at.Add(t.period)
+		}
+		if t.at.After(f.now) {
+			pending = append(pending, t)
+		}
+	}
+	f.timers = pending
+}
+
+// Waiters returns the number of timers and tickers that have not fired or
+// been stopped, so tests can wait for the code under test to start waiting
+// before advancing the clock.
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	c      chan time.Time
+	at     time.Time
+	period time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, other := range f.timers {
+		if other == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	configMapsPath = apiPrefix + "/namespaces/%s/configmaps"
+	configMapPath  = apiPrefix + "/namespaces/%s/configmaps/%s"
+)
+
+// ConfigMap holds configuration data for pods to consume. The api package
+// predates it.
+type ConfigMap struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Data           map[string]string `json:"data,omitempty"`
+	BinaryData     map[string][]byte `json:"binaryData,omitempty"`
+	Immutable      *bool             `json:"immutable,omitempty"`
+}
+
+// Bytes returns the value of key, whether it is held in Data or
+// BinaryData, and whether it was found.
+func (cm *ConfigMap) Bytes(key string) ([]byte, bool) {
+	if v, ok := cm.BinaryData[key]; ok {
+		return v, true
+	}
+	if v, ok := cm.Data[key]; ok {
+		return []byte(v), true
+	}
+	return nil, false
+}
+
+// SetBytes sets key to value, in Data if value is valid UTF-8 and in
+// BinaryData otherwise, since encoding Data as JSON would replace invalid
+// UTF-8 and corrupt the value. Any value key had in the other map is
+// removed.
+func (cm *ConfigMap) SetBytes(key string, value []byte) {
+	if utf8.Valid(value) {
+		delete(cm.BinaryData, key)
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[key] = string(value)
+		return
+	}
+	delete(cm.Data, key)
+	if cm.BinaryData == nil {
+		cm.BinaryData = make(map[string][]byte)
+	}
+	cm.BinaryData[key] = value
+}
+
+// validateConfigMapData fails, before it is sent, for a config map that
+// holds a key in both Data and BinaryData, which the apiserver rejects, or
+// non-UTF-8 data in Data, which would be corrupted.
+func validateConfigMapData(cm *ConfigMap) error {
+	for k, v := range cm.Data {
+		if _, ok := cm.BinaryData[k]; ok {
+			return fmt.Errorf("config map %s has key %q in both data and binaryData", cm.Name, k)
+		}
+		if !utf8.ValidString(v) {
+			return fmt.Errorf("config map %s has non-UTF-8 data in key %q; use binaryData or SetBytes", cm.Name, k)
+		}
+	}
+	return nil
+}
+
+func (c *Client) CreateConfigMap(ctx context.Context, configMap *ConfigMap) (*ConfigMap, error) {
+	if err := validateConfigMapData(configMap); err != nil {
+		return nil, err
+	}
+	var configMapJSON bytes.Buffer
+	if err := json.NewEncoder(&configMapJSON).Encode(configMap); err != nil {
+		return nil, fmt.Errorf("failed to encode configmap in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", configMap.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &ConfigMapResource{c.Host, namespace, ""}, configMapJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.configMapURL(namespace, configMap.Name), configMap)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var configMapResult ConfigMap
+	if err := json.Unmarshal(apiResult, &configMapResult); err != nil {
+		return nil, fmt.Errorf("failed to decode configmap resources: %v", err)
+	}
+	return &configMapResult, nil
+}
+
+// UpdateConfigMap replaces the specified config map. The config map's
+// ResourceVersion guards against overwriting concurrent changes.
+func (c *Client) UpdateConfigMap(ctx context.Context, configMap *ConfigMap) (*ConfigMap, error) {
+	if err := validateConfigMapData(configMap); err != nil {
+		return nil, err
+	}
+	var configMapJSON bytes.Buffer
+	if err := json.NewEncoder(&configMapJSON).Encode(configMap); err != nil {
+		return nil, fmt.Errorf("failed to encode configmap in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", configMap.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	url := c.configMapURL(namespace, configMap.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, configMapJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, configMap)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var configMapResult ConfigMap
+	if err := json.Unmarshal(apiResult, &configMapResult); err != nil {
+		return nil, fmt.Errorf("failed to decode configmap resources: %v", err)
+	}
+	return &configMapResult, nil
+}
+
+// DeleteConfigMap deletes the specified config map.
+func (c *Client) DeleteConfigMap(ctx context.Context, namespace, configMapName string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.configMapURL(namespace, configMapName), firstDeleteOptions(opts), c.Client)
+}
+
+// GetConfigMap gets the specified config map.
+func (c *Client) GetConfigMap(ctx context.Context, namespace, configMapName string) (*ConfigMap, error) {
+	apiResult, err := GetKubeResource(ctx, c.configMapURL(namespace, configMapName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var configMap ConfigMap
+	if err := json.Unmarshal(apiResult, &configMap); err != nil {
+		return nil, fmt.Errorf("failed to decode configmap json: %v", err)
+	}
+	return &configMap, nil
+}
+
+func (c *Client) ListConfigMaps(ctx context.Context, namespace, label string, opts ...ListOptions) ([]ConfigMap, error) {
+	var configMaps []ConfigMap
+
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &ConfigMapResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return configMaps, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var configMapList struct {
+		Items []ConfigMap `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &configMapList); err != nil {
+		return configMaps, fmt.Errorf("failed to decode configmap resources: %v", err)
+	}
+
+	return configMapList.Items, nil
+}
+
+type ConfigMapResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (configMap *ConfigMapResource) KubeResourcesURL() string {
+	return configMap.Host + fmt.Sprintf(configMapsPath, configMap.Namespace)
+}
+
+func (configMap *ConfigMapResource) KubeResourceNamespace() string {
+	return configMap.Namespace
+}
+
+func (configMap *ConfigMapResource) KubeResourceLabel() string {
+	return configMap.Label
+}
+
+func (c *Client) configMapURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(configMapPath, namespace, name)
+}
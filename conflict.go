@@ -0,0 +1,53 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// maxConflictDiffs bounds the number of fields listed in a conflict error.
+const maxConflictDiffs = 10
+
+// conflictError returns err with a description of how the live object at url
+// differs from desired appended, if err is an AlreadyExists or Conflict
+// response and c.ConflictDiagnostics is set. Failures to fetch the live
+// object are noted rather than returned, so err is never lost.
+func (c *Client) conflictError(ctx context.Context, err error, url string, desired interface{}) error {
+	if !c.ConflictDiagnostics || !isConflict(err) {
+		return err
+	}
+	apiResult, getErr := GetKubeResource(ctx, url, c.Client)
+	if getErr != nil {
+		return fmt.Errorf("%w\nfailed to fetch live object: %v", err, getErr)
+	}
+	var live map[string]interface{}
+	if decodeErr := json.Unmarshal(apiResult, &live); decodeErr != nil {
+		return fmt.Errorf("%w\nfailed to decode live object json: %v", err, decodeErr)
+	}
+	want, fieldsErr := objectFields(desired)
+	if fieldsErr != nil {
+		return fmt.Errorf("%w\n%v", err, fieldsErr)
+	}
+
+	var lines []string
+	liveMeta, _ := live["metadata"].(map[string]interface{})
+	wantMeta, _ := want["metadata"].(map[string]interface{})
+	if rv, _ := wantMeta["resourceVersion"].(string); rv != "" && rv != liveMeta["resourceVersion"] {
+		lines = append(lines, fmt.Sprintf("metadata.resourceVersion: live %s, desired %s", diffValue(liveMeta["resourceVersion"]), diffValue(rv)))
+	}
+	diffs := fieldDiffs(live, want)
+	for i, d := range diffs {
+		if i == maxConflictDiffs {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(diffs)-i))
+			break
+		}
+		lines = append(lines, d.String())
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("%w\nlive object matches desired spec, labels, and annotations", err)
+	}
+	return fmt.Errorf("%w\nlive object differs:\n  %s", err, strings.Join(lines, "\n  "))
+}
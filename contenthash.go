@@ -0,0 +1,200 @@
+package kubeclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	// ContentHashAnnotation holds the ContentHash of a secret's or config
+	// map's data.
+	ContentHashAnnotation = "kubeclient/content-hash"
+	// contentHashTemplatePrefix prefixes the pod template annotation that
+	// records the hash of each secret a workload was rolled for.
+	contentHashTemplatePrefix = "kubeclient/content-hash-"
+	// configMapHashTemplatePrefix is contentHashTemplatePrefix for config
+	// maps, kept apart so a secret and a config map may share a name.
+	configMapHashTemplatePrefix = "kubeclient/configmap-content-hash-"
+)
+
+// RolloutTarget identifies a workload, such as a replication controller,
+// whose pods should be replaced when content they consume changes.
+type RolloutTarget struct {
+	Resource KubeResource
+	Name     string
+}
+
+// ContentHash returns a hex encoded SHA-256 hash of data that does not
+// depend on map ordering.
+func ContentHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		// Length prefixes keep {"a": "bc"} and {"ab": "c"} distinct.
+		fmt.Fprintf(h, "%d:%s%d:", len(k), k, len(data[k]))
+		h.Write(data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// UpdateSecretIfChanged replaces the existing secret with secret unless its
+// data is unchanged, as recorded by the ContentHashAnnotation of the live
+// secret. When the secret is updated, the pod template of every rollout
+// target is annotated with the new hash, which makes workloads that roll
+// out on template changes replace their pods. Targets whose template does
+// not yet carry the hash, because an earlier call failed part way, are
+// rolled out even if the secret is unchanged. It reports whether the secret
+// was updated. secret itself is left unchanged.
+func (c *Client) UpdateSecretIfChanged(ctx context.Context, secret *api.Secret, rollouts ...RolloutTarget) (bool, error) {
+	hash := ContentHash(secret.Data)
+	namespace, err := c.resolveNamespace("", secret.Namespace)
+	if err != nil {
+		return false, err
+	}
+	live, err := c.GetSecret(ctx, namespace, secret.Name)
+	if err != nil {
+		return false, err
+	}
+	if live.Annotations[ContentHashAnnotation] == hash {
+		// Finish rollouts an earlier call failed to make.
+		if err := c.rollOut(ctx, rollouts, contentHashTemplatePrefix+secret.Name, hash); err != nil {
+			return false, fmt.Errorf("secret %s unchanged but %w", secret.Name, err)
+		}
+		return false, nil
+	}
+
+	updated := *secret
+	updated.Annotations = withContentHash(secret.Annotations, hash)
+	if updated.ResourceVersion == "" {
+		updated.ResourceVersion = live.ResourceVersion
+	}
+	if _, err := c.UpdateSecret(ctx, &updated); err != nil {
+		return false, err
+	}
+	if err := c.rollOut(ctx, rollouts, contentHashTemplatePrefix+secret.Name, hash); err != nil {
+		return true, fmt.Errorf("secret %s updated but %w", secret.Name, err)
+	}
+	return true, nil
+}
+
+// UpdateConfigMapIfChanged is UpdateSecretIfChanged for config maps, for
+// workloads that read their configuration only at startup. The hash covers
+// both Data and BinaryData.
+func (c *Client) UpdateConfigMapIfChanged(ctx context.Context, configMap *ConfigMap, rollouts ...RolloutTarget) (bool, error) {
+	data := make(map[string][]byte, len(configMap.Data)+len(configMap.BinaryData))
+	for k, v := range configMap.Data {
+		data[k] = []byte(v)
+	}
+	for k, v := range configMap.BinaryData {
+		// Keys are unique across Data and BinaryData.
+		data[k] = v
+	}
+	hash := ContentHash(data)
+	namespace, err := c.resolveNamespace("", configMap.Namespace)
+	if err != nil {
+		return false, err
+	}
+	live, err := c.GetConfigMap(ctx, namespace, configMap.Name)
+	if err != nil {
+		return false, err
+	}
+	if live.Annotations[ContentHashAnnotation] == hash {
+		// Finish rollouts an earlier call failed to make.
+		if err := c.rollOut(ctx, rollouts, configMapHashTemplatePrefix+configMap.Name, hash); err != nil {
+			return false, fmt.Errorf("config map %s unchanged but %w", configMap.Name, err)
+		}
+		return false, nil
+	}
+
+	updated := *configMap
+	updated.Annotations = withContentHash(configMap.Annotations, hash)
+	if updated.ResourceVersion == "" {
+		updated.ResourceVersion = live.ResourceVersion
+	}
+	if _, err := c.UpdateConfigMap(ctx, &updated); err != nil {
+		return false, err
+	}
+	if err := c.rollOut(ctx, rollouts, configMapHashTemplatePrefix+configMap.Name, hash); err != nil {
+		return true, fmt.Errorf("config map %s updated but %w", configMap.Name, err)
+	}
+	return true, nil
+}
+
+// withContentHash returns a copy of annotations with the
+// ContentHashAnnotation set to hash.
+func withContentHash(annotations map[string]string, hash string) map[string]string {
+	copied := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		copied[k] = v
+	}
+	copied[ContentHashAnnotation] = hash
+	return copied
+}
+
+// rollOut annotates the pod template of every target with key set to hash,
+// skipping targets already annotated with it.
+func (c *Client) rollOut(ctx context.Context, targets []RolloutTarget, key, hash string) error {
+	for _, target := range targets {
+		current, err := c.podTemplateAnnotation(ctx, target, key)
+		if err != nil {
+			return fmt.Errorf("rollout of %s failed: %w", target.Name, err)
+		}
+		if current == hash {
+			continue
+		}
+		if err := c.annotatePodTemplate(ctx, target, key, hash); err != nil {
+			return fmt.Errorf("rollout of %s failed: %w", target.Name, err)
+		}
+	}
+	return nil
+}
+
+// podTemplateAnnotation gets the annotation key of the pod template of
+// target, or "" if it is not set.
+func (c *Client) podTemplateAnnotation(ctx context.Context, target RolloutTarget, key string) (string, error) {
+	apiResult, err := GetKubeResource(ctx, target.Resource.KubeResourcesURL()+"/"+target.Name, c.Client)
+	if err != nil {
+		return "", fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var workload struct {
+		Spec struct {
+			Template struct {
+				Metadata struct {
+					Annotations map[string]string `json:"annotations"`
+				} `json:"metadata"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(apiResult, &workload); err != nil {
+		return "", fmt.Errorf("failed to decode %s json: %v", target.Name, err)
+	}
+	return workload.Spec.Template.Metadata.Annotations[key], nil
+}
+
+// annotatePodTemplate sets an annotation on the pod template of target with
+// a merge patch.
+func (c *Client) annotatePodTemplate(ctx context.Context, target RolloutTarget, key, value string) error {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{key: value},
+				},
+			},
+		},
+	}
+	url := target.Resource.KubeResourcesURL() + "/" + target.Name
+	_, err := mergePatchKubeResource(ctx, url, patch, c.Client)
+	return err
+}
@@ -0,0 +1,225 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// UseCostLabels stamps every object created through c with labels, such as
+// {"team": "payments", "project": "ledger", "environment": "prod"}, for
+// cost allocation. The labels are also added to the pod templates of
+// workloads, so their pods carry them too. Labels an object already sets
+// are left alone.
+func (c *Client) UseCostLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if err := validateRequirement(k, "=", []string{v}); err != nil {
+			return fmt.Errorf("invalid cost label: %v", err)
+		}
+	}
+	c.stampLabels(labels)
+	return nil
+}
+
+// stampLabels has every object created through c, and the pod templates of
+// workloads, get the labels they do not already set.
+func (c *Client) stampLabels(labels map[string]string) {
+	stamped := make(map[string]string, len(labels))
+	for k, v := range labels {
+		stamped[k] = v
+	}
+	c.Client.Transport = &labelTransport{labels: stamped, rt: c.Client.Transport}
+}
+
+// labelTransport adds labels to the objects in create requests.
+type labelTransport struct {
+	labels map[string]string
+	rt     http.RoundTripper
+}
+
+func (t *labelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != "POST" || req.Body == nil || strings.Contains(requestKey(req).Resource, "/") {
+		return t.rt.RoundTrip(req)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err == nil && obj != nil {
+		t.stamp(obj)
+		if spec, ok := obj["spec"].(map[string]interface{}); ok {
+			if template, ok := spec["template"].(map[string]interface{}); ok {
+				t.stamp(template)
+			}
+		}
+		if stamped, err := json.Marshal(obj); err == nil {
+			body = stamped
+		}
+	}
+
+	// RoundTrippers must not modify the request they are given.
+	r := new(http.Request)
+	*r = *req
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return t.rt.RoundTrip(r)
+}
+
+// stamp adds the labels missing from obj's metadata.
+func (t *labelTransport) stamp(obj map[string]interface{}) {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		obj["metadata"] = metadata
+	}
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if labels == nil {
+		labels = map[string]interface{}{}
+		metadata["labels"] = labels
+	}
+	for k, v := range t.labels {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+}
+
+func (t *labelTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
+
+// CostAllocation is the resources requested by the pods sharing a set of
+// cost label values.
+type CostAllocation struct {
+	// Labels holds the value of each requested label key; pods without a
+	// key are grouped under "".
+	Labels map[string]string
+	Pods   int
+	// Requests sums the pods' requests by resource name, in cores for
+	// "cpu" and bytes for "memory".
+	Requests map[string]float64
+}
+
+// requestedPod is the part of a pod CostReport needs; the api package
+// does not decode resource quantities.
+type requestedPod struct {
+	Metadata struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Containers     []requestedContainer `json:"containers"`
+		InitContainers []requestedContainer `json:"initContainers"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+type requestedContainer struct {
+	Resources struct {
+		Requests map[string]string `json:"requests"`
+	} `json:"resources"`
+}
+
+// CostReport aggregates the resource requests of the pending and running
+// pods in namespace (all namespaces if empty) by the values of the given
+// label keys, for chargeback. A pod requests the sum of its containers'
+// requests, or the largest of its init containers' if that is more.
+// Allocations are sorted by their label values.
+func (c *Client) CostReport(ctx context.Context, namespace string, keys ...string) ([]CostAllocation, error) {
+	resource := &APIResource{Host: c.Host, Version: "v1", Resource: "pods", Namespace: namespace}
+	apiResult, err := ListKubeResources(ctx, resource, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var podList struct {
+		Items []requestedPod `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &podList); err != nil {
+		return nil, fmt.Errorf("failed to decode pod resources: %v", err)
+	}
+
+	byGroup := map[string]*CostAllocation{}
+	totals := map[string]map[string]*big.Rat{}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != "Pending" && pod.Status.Phase != "Running" {
+			continue
+		}
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			values[i] = pod.Metadata.Labels[k]
+		}
+		group := strings.Join(values, "\x00")
+		alloc := byGroup[group]
+		if alloc == nil {
+			alloc = &CostAllocation{Labels: make(map[string]string, len(keys))}
+			for i, k := range keys {
+				alloc.Labels[k] = values[i]
+			}
+			byGroup[group] = alloc
+			totals[group] = map[string]*big.Rat{}
+		}
+		alloc.Pods++
+		for name, q := range podRequests(pod) {
+			if totals[group][name] == nil {
+				totals[group][name] = new(big.Rat)
+			}
+			totals[group][name].Add(totals[group][name], q)
+		}
+	}
+
+	groups := make([]string, 0, len(byGroup))
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	allocations := make([]CostAllocation, len(groups))
+	for i, group := range groups {
+		alloc := byGroup[group]
+		alloc.Requests = make(map[string]float64, len(totals[group]))
+		for name, total := range totals[group] {
+			alloc.Requests[name], _ = total.Float64()
+		}
+		allocations[i] = *alloc
+	}
+	return allocations, nil
+}
+
+// podRequests returns the effective resource requests of pod.
+func podRequests(pod requestedPod) map[string]*big.Rat {
+	requests := map[string]*big.Rat{}
+	for _, container := range pod.Spec.Containers {
+		for name, s := range container.Resources.Requests {
+			q, ok := parseQuantity(s)
+			if !ok {
+				continue
+			}
+			if requests[name] == nil {
+				requests[name] = new(big.Rat)
+			}
+			requests[name].Add(requests[name], q)
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, s := range container.Resources.Requests {
+			q, ok := parseQuantity(s)
+			if ok && (requests[name] == nil || q.Cmp(requests[name]) > 0) {
+				requests[name] = q
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,108 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/wearemolecule/kubeclient/labels"
+	"golang.org/x/net/context"
+)
+
+// Labels UseDeployIdentity stamps on created objects, along with
+// labels.AppManagedBy for the deploying tool.
+const (
+	DeployVersionLabel = "kubeclient/deploy-version"
+	DeployRunLabel     = "kubeclient/deploy-run"
+)
+
+// DeployIdentity identifies the deploy that created an object: the tool that
+// ran it, the tool's version, and the ID of the pipeline run.
+type DeployIdentity struct {
+	Tool    string
+	Version string
+	RunID   string
+}
+
+// deployExcluded lists the inventory resources ListByDeploy skips.
+// Endpoints are kept by the endpoints controller, which copies the labels of
+// their service, so they were never created by the deploy itself.
+var deployExcluded = map[string]bool{
+	"endpoints": true,
+}
+
+// UseDeployIdentity stamps every object created through c, and the pods of
+// the workloads among them, with labels identifying the deploy, so that its
+// artifacts can be found with ListByDeploy and cleaned up together. The run
+// ID is required; an empty tool or version is not stamped. Labels an object
+// already sets are left alone.
+func (c *Client) UseDeployIdentity(id DeployIdentity) error {
+	if id.RunID == "" {
+		return errors.New("deploy identity has no run ID")
+	}
+	stamped := map[string]string{DeployRunLabel: id.RunID}
+	if id.Tool != "" {
+		stamped[labels.AppManagedBy] = id.Tool
+	}
+	if id.Version != "" {
+		stamped[DeployVersionLabel] = id.Version
+	}
+	for k, v := range stamped {
+		if err := validateRequirement(k, "=", []string{v}); err != nil {
+			return fmt.Errorf("invalid deploy identity: %v", err)
+		}
+	}
+	c.stampLabels(stamped)
+	return nil
+}
+
+// ListByDeploy finds the objects in every namespace that the deploy with
+// runID created, and returns their "namespace/name"s grouped by resource.
+// Resources with no such objects are left out. If ctx is done first, the
+// resources listed so far are returned with an *ErrPartial.
+func (c *Client) ListByDeploy(ctx context.Context, runID string) (Inventory, error) {
+	sel := NewSelector().Equals(DeployRunLabel, runID)
+	if err := sel.Err(); err != nil {
+		return nil, err
+	}
+	resources := make([]string, 0, len(inventoryResources))
+	for resource := range inventoryResources {
+		if !deployExcluded[resource] {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Strings(resources)
+
+	inv := Inventory{}
+	for i, resource := range resources {
+		apiResult, err := ListKubeResources(ctx, WithSelector(inventoryResource(resource, c.Host, ""), sel), c.Client)
+		if err != nil {
+			if ctx.Err() != nil {
+				return inv, &ErrPartial{Missing: resources[i:], Err: ctx.Err()}
+			}
+			return nil, fmt.Errorf("Resource List failed for %s: %w", resource, err)
+		}
+		var list struct {
+			Items []struct {
+				Metadata struct {
+					Name      string `json:"name"`
+					Namespace string `json:"namespace"`
+				} `json:"metadata"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal(apiResult, &list); err != nil {
+			return nil, fmt.Errorf("failed to decode %s resources: %v", resource, err)
+		}
+		if len(list.Items) == 0 {
+			continue
+		}
+		names := make([]string, len(list.Items))
+		for i, item := range list.Items {
+			names[i] = item.Metadata.Namespace + "/" + item.Metadata.Name
+		}
+		sort.Strings(names)
+		inv[resource] = names
+	}
+	return inv, nil
+}
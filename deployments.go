@@ -0,0 +1,234 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	appsPrefix      = apisPath + "/apps/v1"
+	deploymentsPath = appsPrefix + "/namespaces/%s/deployments"
+	deploymentPath  = appsPrefix + "/namespaces/%s/deployments/%s"
+)
+
+// Deployment is an apps/v1 Deployment, which the api package predates.
+type Deployment struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Spec           DeploymentSpec   `json:"spec,omitempty"`
+	Status         DeploymentStatus `json:"status,omitempty"`
+}
+
+type DeploymentSpec struct {
+	Replicas                *int                `json:"replicas,omitempty"`
+	Selector                *LabelSelector      `json:"selector"`
+	Template                api.PodTemplateSpec `json:"template"`
+	Strategy                *DeploymentStrategy `json:"strategy,omitempty"`
+	MinReadySeconds         int                 `json:"minReadySeconds,omitempty"`
+	RevisionHistoryLimit    *int                `json:"revisionHistoryLimit,omitempty"`
+	Paused                  bool                `json:"paused,omitempty"`
+	ProgressDeadlineSeconds *int                `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// DeploymentStrategy describes how pods are replaced; Type is "Recreate" or
+// "RollingUpdate".
+type DeploymentStrategy struct {
+	Type          string                   `json:"type,omitempty"`
+	RollingUpdate *RollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdateDeployment limits unavailable and surge pods; each value is a
+// count (e.g. 1) or a percentage string (e.g. "25%").
+type RollingUpdateDeployment struct {
+	MaxUnavailable interface{} `json:"maxUnavailable,omitempty"`
+	MaxSurge       interface{} `json:"maxSurge,omitempty"`
+}
+
+type DeploymentStatus struct {
+	ObservedGeneration  int64                 `json:"observedGeneration,omitempty"`
+	Replicas            int                   `json:"replicas,omitempty"`
+	UpdatedReplicas     int                   `json:"updatedReplicas,omitempty"`
+	ReadyReplicas       int                   `json:"readyReplicas,omitempty"`
+	AvailableReplicas   int                   `json:"availableReplicas,omitempty"`
+	UnavailableReplicas int                   `json:"unavailableReplicas,omitempty"`
+	Conditions          []DeploymentCondition `json:"conditions,omitempty"`
+}
+
+type DeploymentCondition struct {
+	Type    string              `json:"type"`
+	Status  api.ConditionStatus `json:"status"`
+	Reason  string              `json:"reason,omitempty"`
+	Message string              `json:"message,omitempty"`
+}
+
+// LabelSelector is the set based selector used by apps/v1 objects.
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// String renders the selector in the labelSelector query syntax, e.g.
+// "app=web,tier in (backend,cache)". A nil or empty selector renders as ""
+// and matches everything.
+func (s *LabelSelector) String() string {
+	if s == nil {
+		return ""
+	}
+	var parts []string
+	keys := make([]string, 0, len(s.MatchLabels))
+	for k := range s.MatchLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+s.MatchLabels[k])
+	}
+	for _, r := range s.MatchExpressions {
+		switch r.Operator {
+		case "In":
+			parts = append(parts, r.Key+" in ("+strings.Join(r.Values, ",")+")")
+		case "NotIn":
+			parts = append(parts, r.Key+" notin ("+strings.Join(r.Values, ",")+")")
+		case "Exists":
+			parts = append(parts, r.Key)
+		case "DoesNotExist":
+			parts = append(parts, "!"+r.Key)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+type DeploymentResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (d *DeploymentResource) KubeResourcesURL() string {
+	return d.Host + fmt.Sprintf(deploymentsPath, d.Namespace)
+}
+
+func (d *DeploymentResource) KubeResourceNamespace() string {
+	return d.Namespace
+}
+
+func (d *DeploymentResource) KubeResourceLabel() string {
+	return d.Label
+}
+
+func (c *Client) CreateDeployment(ctx context.Context, deployment *Deployment) (*Deployment, error) {
+	var deploymentJSON bytes.Buffer
+	if err := json.NewEncoder(&deploymentJSON).Encode(deployment); err != nil {
+		return nil, fmt.Errorf("failed to encode deployment in json: %v", err)
+	}
+
+	namespace, err := c.resolveNamespace("", deployment.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &DeploymentResource{c.Host, namespace, ""}, deploymentJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.deploymentURL(namespace, deployment.Name), deployment)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+
+	var deploymentResult Deployment
+	if err := json.Unmarshal(apiResult, &deploymentResult); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment resources: %v", err)
+	}
+	return &deploymentResult, nil
+}
+
+func (c *Client) GetDeployment(ctx context.Context, namespace, name string) (*Deployment, error) {
+	apiResult, err := GetKubeResource(ctx, c.deploymentURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var deployment Deployment
+	if err := json.Unmarshal(apiResult, &deployment); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment json: %v", err)
+	}
+	return &deployment, nil
+}
+
+// UpdateDeployment replaces the deployment. Its ResourceVersion guards
+// against overwriting concurrent changes.
+func (c *Client) UpdateDeployment(ctx context.Context, deployment *Deployment) (*Deployment, error) {
+	var deploymentJSON bytes.Buffer
+	if err := json.NewEncoder(&deploymentJSON).Encode(deployment); err != nil {
+		return nil, fmt.Errorf("failed to encode deployment in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", deployment.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	url := c.deploymentURL(namespace, deployment.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, deploymentJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, deployment)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var deploymentResult Deployment
+	if err := json.Unmarshal(apiResult, &deploymentResult); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment resources: %v", err)
+	}
+	return &deploymentResult, nil
+}
+
+func (c *Client) DeleteDeployment(ctx context.Context, namespace, deploymentName string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.deploymentURL(namespace, deploymentName), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) DeploymentList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]Deployment, error) {
+	var deployments []Deployment
+
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &DeploymentResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return deployments, fmt.Errorf("Resource List failed: %w", err)
+	}
+
+	var deploymentList struct {
+		Items []Deployment `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &deploymentList); err != nil {
+		return deployments, fmt.Errorf("failed to decode deployment resources: %v", err)
+	}
+
+	return deploymentList.Items, nil
+}
+
+// ScaleDeployment sets the number of replicas of the deployment through its
+// scale subresource, leaving the rest of its spec untouched.
+func (c *Client) ScaleDeployment(ctx context.Context, namespace, deploymentName string, replicas int) error {
+	_, err := c.Scale(ctx, &DeploymentResource{c.Host, namespace, ""}, deploymentName, replicas)
+	return err
+}
+
+// DeploymentAvailable reports whether the deployment controller has acted
+// on the latest spec and every desired replica is updated and available.
+func DeploymentAvailable(d *Deployment) bool {
+	replicas := 1
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas >= replicas &&
+		d.Status.AvailableReplicas >= replicas &&
+		d.Status.Replicas == d.Status.UpdatedReplicas
+}
+
+func (c *Client) deploymentURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(deploymentPath, namespace, name)
+}
@@ -0,0 +1,94 @@
+package kubeclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// DialUnixSocket routes every apiserver connection over the unix domain
+// socket at socketPath. c.Host is still used to build request URLs, so a
+// socket exposed by `kubectl proxy --unix-socket` is typically paired with a
+// Host of "http://localhost".
+func (c *Client) DialUnixSocket(socketPath string) error {
+	tr, err := c.transport()
+	if err != nil {
+		return err
+	}
+	tr.Dial = func(network, addr string) (net.Conn, error) {
+		return net.Dial("unix", socketPath)
+	}
+	return nil
+}
+
+// DialAddress routes every apiserver connection to addr (host:port)
+// regardless of the host in the request URL. This is useful for port-forwards
+// and tunnels where c.Host keeps the apiserver's real name so the Host header
+// and TLS verification still match its certificate.
+func (c *Client) DialAddress(addr string) error {
+	tr, err := c.transport()
+	if err != nil {
+		return err
+	}
+	tr.Dial = func(network, _ string) (net.Conn, error) {
+		return net.Dial(network, addr)
+	}
+	return nil
+}
+
+// OverrideServerName sends serverName as the Host header and TLS SNI, and
+// verifies the apiserver certificate against it, while c.Host is left
+// pointing at the local end of a port-forward (e.g. https://127.0.0.1:6443).
+func (c *Client) OverrideServerName(serverName string) error {
+	tr, err := c.transport()
+	if err != nil {
+		return err
+	}
+	if tr.TLSClientConfig != nil {
+		tr.TLSClientConfig.ServerName = serverName
+	}
+	c.Client.Transport = &hostHeaderTransport{host: serverName, rt: c.Client.Transport}
+	return nil
+}
+
+// wrappedTransport is implemented by the RoundTrippers this package layers on
+// top of the client's *http.Transport.
+type wrappedTransport interface {
+	unwrap() http.RoundTripper
+}
+
+// transport returns the *http.Transport underlying c.Client so that
+// connection level settings can be changed after construction.
+func (c *Client) transport() (*http.Transport, error) {
+	if c.Client == nil {
+		return nil, fmt.Errorf("client has no http.Client")
+	}
+	rt := c.Client.Transport
+	for {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t, nil
+		case wrappedTransport:
+			rt = t.unwrap()
+		default:
+			return nil, fmt.Errorf("unsupported transport %T, need *http.Transport", rt)
+		}
+	}
+}
+
+type hostHeaderTransport struct {
+	host string
+	rt   http.RoundTripper
+}
+
+func (t *hostHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request.
+	r := new(http.Request)
+	*r = *req
+	r.Host = t.host
+	return t.rt.RoundTrip(r)
+}
+
+func (t *hostHeaderTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
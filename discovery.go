@@ -0,0 +1,63 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/net/context"
+)
+
+const apisPath = "/apis"
+
+// APIGroup is an API group served by the apiserver, including those served
+// by aggregated API servers.
+type APIGroup struct {
+	Name             string                     `json:"name"`
+	Versions         []GroupVersionForDiscovery `json:"versions"`
+	PreferredVersion GroupVersionForDiscovery   `json:"preferredVersion"`
+}
+
+type GroupVersionForDiscovery struct {
+	// GroupVersion is "<group>/<version>", e.g. "metrics.k8s.io/v1beta1".
+	GroupVersion string `json:"groupVersion"`
+	Version      string `json:"version"`
+}
+
+// APIGroups lists the API groups the apiserver serves, apart from the core
+// group.
+func (c *Client) APIGroups(ctx context.Context) ([]APIGroup, error) {
+	apiResult, err := GetKubeResource(ctx, c.Host+apisPath, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var groupList struct {
+		Groups []APIGroup `json:"groups"`
+	}
+	if err := json.Unmarshal(apiResult, &groupList); err != nil {
+		return nil, fmt.Errorf("failed to decode api group list: %v", err)
+	}
+	return groupList.Groups, nil
+}
+
+// APIGroupPrefix returns the path prefix for the preferred version of the
+// API group, e.g. "/apis/metrics.k8s.io/v1beta1". If the group's server is
+// an unavailable aggregated server, an *APIServiceUnavailableError is
+// returned.
+func (c *Client) APIGroupPrefix(ctx context.Context, group string) (string, error) {
+	groups, err := c.APIGroups(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, g := range groups {
+		if g.Name != group {
+			continue
+		}
+		if err := c.CheckAPIService(ctx, group, g.PreferredVersion.Version); err != nil {
+			if _, ok := err.(*APIServiceUnavailableError); ok {
+				return "", err
+			}
+		}
+		return apisPath + "/" + g.PreferredVersion.GroupVersion, nil
+	}
+	return "", fmt.Errorf("API group %q is not served", group)
+}
@@ -0,0 +1,231 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	// mirrorPodAnnotation marks the apiserver's copies of static pods,
+	// which the kubelet runs from files and which cannot be evicted.
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+	// drainRetryInterval is how often DrainNode retries an eviction that
+	// a PodDisruptionBudget blocked, by default.
+	drainRetryInterval = 5 * time.Second
+)
+
+// EvictPod evicts the pod through its eviction subresource, which, unlike
+// DeletePod, refuses to break a PodDisruptionBudget covering the pod. opts,
+// if not nil, controls the delete the eviction results in. Use
+// IsEvictionBlocked to tell an eviction the budget refused, which may be
+// retried once the budget allows it.
+func (c *Client) EvictPod(ctx context.Context, namespace, name string, opts *DeleteOptions) error {
+	eviction := struct {
+		Kind          string         `json:"kind"`
+		APIVersion    string         `json:"apiVersion"`
+		Metadata      api.ObjectMeta `json:"metadata"`
+		DeleteOptions *DeleteOptions `json:"deleteOptions,omitempty"`
+	}{"Eviction", "policy/v1", api.ObjectMeta{Name: name, Namespace: namespace}, opts}
+	var evictionJSON bytes.Buffer
+	if err := json.NewEncoder(&evictionJSON).Encode(eviction); err != nil {
+		return fmt.Errorf("failed to encode eviction in json: %v", err)
+	}
+	resource := &APIResource{Host: c.Host, Version: "v1", Resource: "pods/" + name + "/eviction", Namespace: namespace}
+	if _, err := CreateKubeResource(ctx, resource, evictionJSON, c.Client); err != nil {
+		return fmt.Errorf("Create failed: %w", err)
+	}
+	return nil
+}
+
+// IsEvictionBlocked reports whether err was caused by an eviction that a
+// PodDisruptionBudget refused (429 Too Many Requests).
+func IsEvictionBlocked(err error) bool {
+	return statusCode(err) == http.StatusTooManyRequests
+}
+
+// DrainOptions controls how DrainNode evicts a node's pods. By default, as
+// with kubectl drain, it refuses to drain a node running pods whose loss
+// cannot be made up for: pods no controller will recreate, pods using
+// emptyDir volumes, and DaemonSet pods, which would be recreated on the
+// node at once.
+type DrainOptions struct {
+	// GracePeriodSeconds overrides the grace period of the evicted pods.
+	GracePeriodSeconds *int64
+	// Force evicts pods that no controller manages, which are gone for
+	// good once evicted.
+	Force bool
+	// IgnoreDaemonSets leaves DaemonSet pods running instead of refusing
+	// to drain the node.
+	IgnoreDaemonSets bool
+	// DeleteEmptyDirData evicts pods using emptyDir volumes, losing their
+	// contents.
+	DeleteEmptyDirData bool
+	// RetryInterval is how often an eviction blocked by a
+	// PodDisruptionBudget is retried, 5s if zero.
+	RetryInterval time.Duration
+}
+
+// drainPod holds the fields of a pod DrainNode checks, some of which the
+// api package predates.
+type drainPod struct {
+	Metadata struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace"`
+		UID             string            `json:"uid"`
+		Annotations     map[string]string `json:"annotations"`
+		OwnerReferences []OwnerReference  `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		Volumes []struct {
+			Name     string           `json:"name"`
+			EmptyDir *json.RawMessage `json:"emptyDir"`
+		} `json:"volumes"`
+	} `json:"spec"`
+	Status struct {
+		Phase api.PodPhase `json:"phase"`
+	} `json:"status"`
+}
+
+// check returns why the pod may not be evicted under opts, or "" if it may.
+// skip is set for pods left running instead.
+func (p *drainPod) check(opts DrainOptions) (skip bool, problem string) {
+	if _, ok := p.Metadata.Annotations[mirrorPodAnnotation]; ok {
+		return true, ""
+	}
+	if p.Status.Phase == api.PodSucceeded || p.Status.Phase == api.PodFailed {
+		// Finished pods have nothing left to lose.
+		return false, ""
+	}
+	var controller *OwnerReference
+	for i, ref := range p.Metadata.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			controller = &p.Metadata.OwnerReferences[i]
+		}
+	}
+	switch {
+	case controller != nil && controller.Kind == "DaemonSet":
+		if opts.IgnoreDaemonSets {
+			return true, ""
+		}
+		return false, "is managed by a DaemonSet"
+	case controller == nil && !opts.Force:
+		return false, "is not managed by a controller"
+	}
+	if !opts.DeleteEmptyDirData {
+		for _, v := range p.Spec.Volumes {
+			if v.EmptyDir != nil {
+				return false, fmt.Sprintf("uses emptyDir volume %s", v.Name)
+			}
+		}
+	}
+	return false, ""
+}
+
+// DrainNode cordons the node and evicts its pods, waiting for each to be
+// deleted. Evictions that a PodDisruptionBudget blocks are retried until
+// it allows them or ctx is done, so bound ctx to bound the drain. If any
+// pod may not be evicted under opts, DrainNode fails, naming them, after
+// cordoning the node but before evicting anything. Pods that fail to be
+// evicted, or deleted, are reported together in an AggregateError once
+// the rest are done. The node stays cordoned whatever happens; see
+// SetNodeUnschedulable to uncordon it.
+func (c *Client) DrainNode(ctx context.Context, nodeName string, opts DrainOptions) error {
+	if _, err := c.SetNodeUnschedulable(ctx, nodeName, true); err != nil {
+		return err
+	}
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &PodResource{c.Host, "", ""}, ListOptions{FieldSelector: "spec.nodeName=" + nodeName}, c.Client)
+	if err != nil {
+		return fmt.Errorf("Resource List failed: %w", err)
+	}
+	var podList struct {
+		Items []drainPod `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &podList); err != nil {
+		return fmt.Errorf("failed to decode pod resources: %v", err)
+	}
+
+	var evict []*drainPod
+	var problems []string
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		skip, problem := pod.check(opts)
+		switch {
+		case problem != "":
+			problems = append(problems, fmt.Sprintf("pod %s/%s %s", pod.Metadata.Namespace, pod.Metadata.Name, problem))
+		case !skip:
+			evict = append(evict, pod)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("cannot drain node %s: %s", nodeName, strings.Join(problems, "; "))
+	}
+
+	errs := make([]error, len(evict))
+	var wg sync.WaitGroup
+	for i, pod := range evict {
+		wg.Add(1)
+		go func(i int, pod *drainPod) {
+			defer wg.Done()
+			errs[i] = c.drainPod(ctx, pod, opts)
+		}(i, pod)
+	}
+	wg.Wait()
+	var failed AggregateError
+	for i, pod := range evict {
+		failed.add("evict", api.ObjectReference{Kind: "Pod", Namespace: pod.Metadata.Namespace, Name: pod.Metadata.Name, UID: pod.Metadata.UID}, errs[i])
+	}
+	if err := failed.err(); err != nil {
+		return fmt.Errorf("failed to drain node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// drainPod evicts the pod, retrying while a PodDisruptionBudget blocks it,
+// and waits for it to be deleted, or replaced by a pod of the same name.
+func (c *Client) drainPod(ctx context.Context, pod *drainPod, opts DrainOptions) error {
+	namespace, name := pod.Metadata.Namespace, pod.Metadata.Name
+	interval := opts.RetryInterval
+	if interval <= 0 {
+		interval = drainRetryInterval
+	}
+	deleteOptions := &DeleteOptions{
+		GracePeriodSeconds: opts.GracePeriodSeconds,
+		Preconditions:      &Preconditions{UID: pod.Metadata.UID},
+	}
+	for {
+		err := c.EvictPod(ctx, namespace, name, deleteOptions)
+		if err == nil {
+			break
+		}
+		if IsNotFound(err) || IsConflict(err) {
+			// The pod is gone already, or was replaced, failing the
+			// UID precondition.
+			return nil
+		}
+		if !IsEvictionBlocked(err) {
+			return err
+		}
+		t := c.clock().NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C():
+		}
+	}
+	_, err := c.AwaitPodCondition(ctx, namespace, name, "", func(p *api.Pod) (bool, error) {
+		return p.UID != pod.Metadata.UID, nil
+	})
+	if err == nil || err == ErrPodDeleted || IsNotFound(err) {
+		return nil
+	}
+	return fmt.Errorf("evicted but not deleted: %w", err)
+}
@@ -0,0 +1,214 @@
+package kubeclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// FieldDiff is a field whose live value differs from the desired one.
+type FieldDiff struct {
+	// Path locates the field, e.g. "spec.containers[0].image".
+	Path    string
+	Live    interface{}
+	Desired interface{}
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: live %s, desired %s", d.Path, diffValue(d.Live), diffValue(d.Desired))
+}
+
+func diffValue(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// specFields are the top level fields that describe what an object should
+// be, as opposed to its metadata and status.
+var specFields = []string{"spec", "data"}
+
+// SpecHash returns a hex encoded SHA-256 hash of the spec (or data, for
+// secrets) of obj, encoded as CanonicalJSON. Identical specs hash
+// identically regardless of map ordering or empty fields.
+func SpecHash(obj interface{}) (string, error) {
+	fields, err := canonicalFields(obj)
+	if err != nil {
+		return "", err
+	}
+	spec := make(map[string]interface{})
+	for _, f := range specFields {
+		if v, ok := fields[f]; ok {
+			spec[f] = v
+		}
+	}
+	b, err := encodeCanonical(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Drifted fetches the live counterpart of desired and reports whether it
+// has diverged, along with the differing fields. Only fields that desired
+// sets are compared: its spec (or data), labels, and annotations. Fields
+// desired leaves empty, which the apiserver may have defaulted, are
+// ignored, and resource quantities are compared by value, so "1000m"
+// matches "1". desired may be any object RefOf supports.
+func (c *Client) Drifted(ctx context.Context, desired interface{}) (bool, []FieldDiff, error) {
+	url, err := c.objectURL(desired)
+	if err != nil {
+		return false, nil, err
+	}
+	apiResult, err := GetKubeResource(ctx, url, c.Client)
+	if err != nil {
+		return false, nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var live map[string]interface{}
+	if err := json.Unmarshal(apiResult, &live); err != nil {
+		return false, nil, fmt.Errorf("failed to decode live object json: %v", err)
+	}
+	want, err := objectFields(desired)
+	if err != nil {
+		return false, nil, err
+	}
+	diffs := fieldDiffs(live, want)
+	return len(diffs) > 0, diffs, nil
+}
+
+// fieldDiffs compares the spec (or data), labels, and annotations that want
+// sets against live.
+func fieldDiffs(live, want map[string]interface{}) []FieldDiff {
+	var diffs []FieldDiff
+	for _, f := range specFields {
+		if v, ok := want[f]; ok {
+			diffs = appendDiffs(diffs, f, live[f], v)
+		}
+	}
+	liveMeta, _ := live["metadata"].(map[string]interface{})
+	wantMeta, _ := want["metadata"].(map[string]interface{})
+	for _, f := range []string{"labels", "annotations"} {
+		if v, ok := wantMeta[f]; ok {
+			diffs = appendDiffs(diffs, "metadata."+f, liveMeta[f], v)
+		}
+	}
+	return diffs
+}
+
+// NeedsRestart reports whether replacing the live pod template of a
+// workload with desired would change its pods, so that deploy tooling only
+// bounces workloads when something meaningful changed. The templates are
+// compared as Drifted compares objects: only the labels, annotations, and
+// spec fields desired sets count, and resource quantities are compared by
+// value.
+func NeedsRestart(live, desired *api.PodTemplateSpec) bool {
+	liveFields, err := objectFields(live)
+	if err != nil {
+		return true
+	}
+	wantFields, err := objectFields(desired)
+	if err != nil {
+		return true
+	}
+	return len(fieldDiffs(liveFields, wantFields)) > 0
+}
+
+// appendDiffs compares the fields desired sets against live, recursing into
+// objects and lists, and appends any differences to diffs.
+func appendDiffs(diffs []FieldDiff, path string, live, desired interface{}) []FieldDiff {
+	switch d := desired.(type) {
+	case nil, bool, float64:
+		if isEmptyValue(d) || reflect.DeepEqual(live, d) {
+			return diffs
+		}
+	case string:
+		if isEmptyValue(d) || reflect.DeepEqual(live, d) {
+			return diffs
+		}
+		if l, ok := live.(string); ok && isQuantityPath(path) && equalQuantities(l, d) {
+			return diffs
+		}
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if len(d) == 0 {
+			return diffs
+		}
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffs = appendDiffs(diffs, path+"."+k, l[k], d[k])
+		}
+		return diffs
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if len(d) == 0 {
+			return diffs
+		}
+		if !ok || len(l) != len(d) {
+			break
+		}
+		for i := range d {
+			diffs = appendDiffs(diffs, fmt.Sprintf("%s[%d]", path, i), l[i], d[i])
+		}
+		return diffs
+	}
+	return append(diffs, FieldDiff{Path: path, Live: live, Desired: desired})
+}
+
+// isQuantityPath reports whether the field at path holds a resource
+// quantity, such as "spec.containers[0].resources.limits.cpu".
+func isQuantityPath(path string) bool {
+	return strings.Contains(path, ".resources.limits.") || strings.Contains(path, ".resources.requests.")
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	}
+	return false
+}
+
+// objectFields returns obj as generic JSON fields.
+func objectFields(obj interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode object in json: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode object json: %v", err)
+	}
+	return fields, nil
+}
+
+// objectURL returns the URL of the live counterpart of obj.
+func (c *Client) objectURL(obj interface{}) (string, error) {
+	ref, err := RefOf(obj)
+	if err != nil {
+		return "", err
+	}
+	return ref.URL(c.Host), nil
+}
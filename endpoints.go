@@ -10,14 +10,15 @@ import (
 
 const (
 	endpointsPath = apiPrefix + "/namespaces/%s/endpoints"
+	endpointPath  = apiPrefix + "/namespaces/%s/endpoints/%s"
 )
 
-func (c *Client) EndpointsList(ctx context.Context, namespace, label string) ([]api.Endpoints, error) {
+func (c *Client) EndpointsList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.Endpoints, error) {
 	var endpoints []api.Endpoints
 
-	apiResult, err := ListKubeResources(ctx, &EndpointResource{c.Host, namespace, ""}, c.Client)
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &EndpointResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
 	if err != nil {
-		return endpoints, fmt.Errorf("Resource List failed: %v", err)
+		return endpoints, fmt.Errorf("Resource List failed: %w", err)
 	}
 
 	var endpointsList api.EndpointsList
@@ -28,6 +29,10 @@ func (c *Client) EndpointsList(ctx context.Context, namespace, label string) ([]
 	return endpointsList.Items, nil
 }
 
+func (c *Client) endpointsURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(endpointPath, namespace, name)
+}
+
 type EndpointResource struct {
 	Host      string
 	Namespace string
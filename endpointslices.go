@@ -0,0 +1,164 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wearemolecule/kubeclient/labels"
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const discoveryGroup = "discovery.k8s.io"
+
+// EndpointSlice is a discovery.k8s.io/v1 EndpointSlice, one of possibly
+// several holding the endpoints of a service. The api package predates it.
+type EndpointSlice struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	// AddressType is "IPv4", "IPv6", or "FQDN".
+	AddressType string              `json:"addressType"`
+	Endpoints   []SliceEndpoint     `json:"endpoints"`
+	Ports       []EndpointSlicePort `json:"ports,omitempty"`
+}
+
+// SliceEndpoint is a single endpoint of an EndpointSlice.
+type SliceEndpoint struct {
+	Addresses  []string             `json:"addresses"`
+	Conditions EndpointConditions   `json:"conditions,omitempty"`
+	Hostname   string               `json:"hostname,omitempty"`
+	TargetRef  *api.ObjectReference `json:"targetRef,omitempty"`
+	NodeName   string               `json:"nodeName,omitempty"`
+	Zone       string               `json:"zone,omitempty"`
+}
+
+// EndpointConditions are the conditions of a SliceEndpoint. Nil means
+// unknown.
+type EndpointConditions struct {
+	Ready       *bool `json:"ready,omitempty"`
+	Serving     *bool `json:"serving,omitempty"`
+	Terminating *bool `json:"terminating,omitempty"`
+}
+
+// EndpointSlicePort is a port of every endpoint in an EndpointSlice.
+type EndpointSlicePort struct {
+	Name     string       `json:"name,omitempty"`
+	Port     int          `json:"port,omitempty"`
+	Protocol api.Protocol `json:"protocol,omitempty"`
+}
+
+// EndpointSliceList lists the EndpointSlices in namespace matching label.
+// The slices of a single service match kubernetes.io/service-name=<service>.
+func (c *Client) EndpointSliceList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]EndpointSlice, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &EndpointSliceResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var sliceList struct {
+		Items []EndpointSlice `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &sliceList); err != nil {
+		return nil, fmt.Errorf("failed to decode endpoint slice resources: %v", err)
+	}
+	return sliceList.Items, nil
+}
+
+// ServiceAddress is an endpoint address of a service, whether it came from
+// an EndpointSlice or a legacy Endpoints object.
+type ServiceAddress struct {
+	IP        string
+	Hostname  string
+	NodeName  string
+	Zone      string
+	Ready     bool
+	TargetRef *api.ObjectReference
+	Ports     []api.EndpointPort
+}
+
+// ServiceAddresses returns the endpoint addresses of the service, ready or
+// not. They are read from the service's EndpointSlices, which are complete
+// even for services large enough to have their Endpoints object truncated,
+// and from the Endpoints object on clusters that do not serve
+// discovery.k8s.io/v1. Zone is only known from EndpointSlices.
+func (c *Client) ServiceAddresses(ctx context.Context, namespace, serviceName string) ([]ServiceAddress, error) {
+	slices, err := c.EndpointSliceList(ctx, namespace, labels.ServiceName+"="+serviceName)
+	if IsNotFound(err) {
+		return c.endpointsAddresses(ctx, namespace, serviceName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []ServiceAddress
+	seen := map[string]bool{}
+	for _, slice := range slices {
+		if slice.AddressType == "FQDN" {
+			continue
+		}
+		var ports []api.EndpointPort
+		for _, p := range slice.Ports {
+			ports = append(ports, api.EndpointPort{Name: p.Name, Port: p.Port, Protocol: p.Protocol})
+		}
+		for _, ep := range slice.Endpoints {
+			for _, ip := range ep.Addresses {
+				// An endpoint may briefly appear in two slices while
+				// it is moved between them.
+				if seen[ip] {
+					continue
+				}
+				seen[ip] = true
+				addresses = append(addresses, ServiceAddress{
+					IP:        ip,
+					Hostname:  ep.Hostname,
+					NodeName:  ep.NodeName,
+					Zone:      ep.Zone,
+					Ready:     ep.Conditions.Ready == nil || *ep.Conditions.Ready,
+					TargetRef: ep.TargetRef,
+					Ports:     ports,
+				})
+			}
+		}
+	}
+	return addresses, nil
+}
+
+func (c *Client) endpointsAddresses(ctx context.Context, namespace, serviceName string) ([]ServiceAddress, error) {
+	apiResult, err := GetKubeResource(ctx, c.endpointsURL(namespace, serviceName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var endpoints api.Endpoints
+	if err := json.Unmarshal(apiResult, &endpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode endpoints json: %v", err)
+	}
+	var addresses []ServiceAddress
+	for _, subset := range endpoints.Subsets {
+		for _, a := range subset.Addresses {
+			addresses = append(addresses, ServiceAddress{IP: a.IP, Ready: true, TargetRef: a.TargetRef, Ports: subset.Ports})
+		}
+		for _, a := range subset.NotReadyAddresses {
+			addresses = append(addresses, ServiceAddress{IP: a.IP, TargetRef: a.TargetRef, Ports: subset.Ports})
+		}
+	}
+	return addresses, nil
+}
+
+// EndpointSliceResource is the KubeResource for EndpointSlices; use it with
+// Watch to follow a service's endpoints.
+type EndpointSliceResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (r *EndpointSliceResource) KubeResourcesURL() string {
+	return r.Host + groupVersionPrefix(discoveryGroup, "v1") + fmt.Sprintf("/namespaces/%s/endpointslices", r.Namespace)
+}
+
+func (r *EndpointSliceResource) KubeResourceNamespace() string {
+	return r.Namespace
+}
+
+func (r *EndpointSliceResource) KubeResourceLabel() string {
+	return r.Label
+}
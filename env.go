@@ -0,0 +1,232 @@
+package kubeclient
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Environment variables FromEnv reads PEM data from.
+const (
+	certDataEnv = "KUBE_CERT_DATA"
+	keyDataEnv  = "KUBE_KEY_DATA"
+	caDataEnv   = "KUBE_CA_DATA"
+)
+
+// envConfig holds what FromEnv builds a client from.
+type envConfig struct {
+	getenv func(string) string
+
+	host      string
+	certFile  string
+	keyFile   string
+	caFile    string
+	certData  []byte
+	keyData   []byte
+	caData    []byte
+	token     string
+	username  string
+	password  string
+	namespace string
+	tunnel    string
+}
+
+// An Option overrides part of the configuration FromEnv reads from the
+// environment.
+type Option func(*envConfig)
+
+// WithGetenv makes FromEnv read environment variables through getenv
+// instead of os.Getenv.
+func WithGetenv(getenv func(string) string) Option {
+	return func(cfg *envConfig) { cfg.getenv = getenv }
+}
+
+// WithHost sets the apiserver URL, e.g. "https://10.0.0.1:443", instead of
+// building it from KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT.
+func WithHost(host string) Option {
+	return func(cfg *envConfig) { cfg.host = host }
+}
+
+// WithCertFiles sets the paths of the PEM-encoded client certificate, key,
+// and CA certificate instead of the files in CERTS_PATH. Empty paths are
+// left at their defaults.
+func WithCertFiles(certFile, keyFile, caFile string) Option {
+	return func(cfg *envConfig) {
+		if certFile != "" {
+			cfg.certFile = certFile
+		}
+		if keyFile != "" {
+			cfg.keyFile = keyFile
+		}
+		if caFile != "" {
+			cfg.caFile = caFile
+		}
+	}
+}
+
+// WithCertData sets the PEM-encoded client certificate, key, and CA
+// certificate, so that no files are read for them. Nil arguments are left
+// at their defaults.
+func WithCertData(certData, keyData, caData []byte) Option {
+	return func(cfg *envConfig) {
+		if certData != nil {
+			cfg.certData = certData
+		}
+		if keyData != nil {
+			cfg.keyData = keyData
+		}
+		if caData != nil {
+			cfg.caData = caData
+		}
+	}
+}
+
+// WithToken authenticates requests with a bearer token. A client certificate
+// is then only used if one is given with WithCertFiles or WithCertData.
+func WithToken(token string) Option {
+	return func(cfg *envConfig) { cfg.token = token }
+}
+
+// WithBasicAuth authenticates requests with a username and password, for
+// old clusters that still accept them. As with WithToken, a client
+// certificate is then only used if one is given with WithCertFiles or
+// WithCertData.
+func WithBasicAuth(username, password string) Option {
+	return func(cfg *envConfig) { cfg.username, cfg.password = username, password }
+}
+
+// WithTunnel routes every apiserver connection to localAddr (host:port), the
+// local end of an SSH tunnel or port-forward to the apiserver. Request URLs,
+// the Host header, and TLS server name verification keep using the real
+// apiserver host, so its certificate still checks out.
+func WithTunnel(localAddr string) Option {
+	return func(cfg *envConfig) { cfg.tunnel = localAddr }
+}
+
+// WithNamespace sets the client's Namespace.
+func WithNamespace(namespace string) Option {
+	return func(cfg *envConfig) { cfg.namespace = namespace }
+}
+
+// FromEnv returns a client configured like GetKubeClientFromEnv, from
+// KUBERNETES_SERVICE_HOST, KUBERNETES_SERVICE_PORT, and the cert.pem,
+// key.pem, and ca.pem files in CERTS_PATH, with any part of that replaced
+// by overrides. The PEM data itself, plain or base64-encoded, may instead
+// be given in KUBE_CERT_DATA, KUBE_KEY_DATA, and KUBE_CA_DATA, for
+// environments where credentials cannot be written to disk. A client
+// certificate read from files is reloaded when the files change.
+func FromEnv(overrides ...Option) (*Client, error) {
+	cfg := envConfig{getenv: os.Getenv}
+	for _, o := range overrides {
+		o(&cfg)
+	}
+
+	var err error
+	// PEM data in the environment takes the place of the matching file in
+	// CERTS_PATH, but not of one set with WithCertFiles.
+	for _, v := range []struct {
+		env  string
+		file string
+		data *[]byte
+	}{
+		{certDataEnv, cfg.certFile, &cfg.certData},
+		{keyDataEnv, cfg.keyFile, &cfg.keyData},
+		{caDataEnv, cfg.caFile, &cfg.caData},
+	} {
+		if *v.data != nil || v.file != "" {
+			continue
+		}
+		if *v.data, err = pemFromEnv(cfg.getenv(v.env)); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", v.env, err)
+		}
+	}
+	explicitCert := cfg.certFile != "" || cfg.keyFile != "" || cfg.certData != nil || cfg.keyData != nil
+
+	certsPath := cfg.getenv("CERTS_PATH")
+	if cfg.host == "" {
+		cfg.host = apiServerURL(cfg.getenv("KUBERNETES_SERVICE_HOST"), cfg.getenv("KUBERNETES_SERVICE_PORT"))
+	}
+	if cfg.certFile == "" {
+		cfg.certFile = fmt.Sprintf("%s/%s", certsPath, "cert.pem")
+	}
+	if cfg.keyFile == "" {
+		cfg.keyFile = fmt.Sprintf("%s/%s", certsPath, "key.pem")
+	}
+	if cfg.caFile == "" {
+		cfg.caFile = fmt.Sprintf("%s/%s", certsPath, "ca.pem")
+	}
+
+	if cfg.caData == nil {
+		if cfg.caData, err = dataFromFile(cfg.caFile); err != nil {
+			return nil, errors.New("Couldn't load CA")
+		}
+	}
+	tlsConfig := &tls.Config{
+		// Change default from SSLv3 to TLSv1.0 (because of POODLE vulnerability)
+		MinVersion: tls.VersionTLS10,
+		RootCAs:    rootCertPool(cfg.caData),
+	}
+
+	switch {
+	case (cfg.token != "" || cfg.username != "") && !explicitCert:
+	case cfg.certData == nil && cfg.keyData == nil:
+		// Files are watched so rotated certificates are picked up.
+		files, err := newKeyPairFiles(cfg.certFile, cfg.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = files.getClientCertificate
+	default:
+		if cfg.certData == nil {
+			if cfg.certData, err = dataFromFile(cfg.certFile); err != nil {
+				return nil, errors.New("Couldn't load certificate")
+			}
+		}
+		if cfg.keyData == nil {
+			if cfg.keyData, err = dataFromFile(cfg.keyFile); err != nil {
+				return nil, errors.New("Couldn't load key")
+			}
+		}
+		cert, err := tls.X509KeyPair(cfg.certData, cfg.keyData)
+		if err != nil {
+			return nil, errors.New("Failed to build X509 KeyPair")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	var tr http.RoundTripper = &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	switch {
+	case cfg.token != "":
+		tr = &authTransport{provider: BearerTokenAuth(cfg.token), rt: tr}
+	case cfg.username != "":
+		tr = &authTransport{provider: BasicAuth(cfg.username, cfg.password), rt: tr}
+	}
+	client := newClient(cfg.host, tr)
+	client.Namespace = cfg.namespace
+	if cfg.tunnel != "" {
+		if err := client.DialAddress(cfg.tunnel); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// pemFromEnv returns the PEM data in an environment variable's value,
+// decoding it first if it is base64-encoded. It returns nil for an empty
+// value.
+func pemFromEnv(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
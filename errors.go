@@ -0,0 +1,98 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+)
+
+// StatusError is returned when the apiserver answers a request with an
+// unexpected status code. Errors returned by the Client methods wrap it, so
+// use errors.As, or helpers such as IsNotFound, to inspect it.
+type StatusError struct {
+	// Code is the HTTP status code, e.g. 404.
+	Code int
+	// Reason is the reason from the api.Status in the response body, e.g.
+	// "NotFound", "AlreadyExists", or "Conflict". It is empty if the body
+	// was not an api.Status.
+	Reason string
+	// Message is the message from the api.Status in the response body, or
+	// the body itself.
+	Message string
+	Method  string
+	URL     string
+}
+
+func (e *StatusError) Error() string {
+	request := e.Method
+	if e.URL != "" {
+		request += fmt.Sprintf(" %q", e.URL)
+	}
+	if e.Reason != "" {
+		return fmt.Sprintf("http error: %d %s: %s: %s", e.Code, request, e.Reason, e.Message)
+	}
+	return fmt.Sprintf("http error: %d %s: %q", e.Code, request, e.Message)
+}
+
+// newStatusError returns the StatusError for a response with the given
+// status code and body.
+func newStatusError(method, url string, code int, body []byte) *StatusError {
+	e := &StatusError{Code: code, Method: method, URL: url, Message: strings.TrimSpace(string(body))}
+	var status api.Status
+	if err := json.Unmarshal(body, &status); err == nil && status.Kind == "Status" {
+		e.Reason = string(status.Reason)
+		e.Message = status.Message
+	}
+	return e
+}
+
+// statusCode returns the status code of the StatusError in err's chain, or
+// 0 if there is none.
+func statusCode(err error) int {
+	var e *StatusError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 0
+}
+
+// statusReason returns the reason of the StatusError in err's chain.
+func statusReason(err error) string {
+	var e *StatusError
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return ""
+}
+
+// IsNotFound reports whether err was caused by a 404 Not Found response.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsAlreadyExists reports whether err was caused by creating an object that
+// already exists.
+func IsAlreadyExists(err error) bool {
+	return statusCode(err) == http.StatusConflict && statusReason(err) == "AlreadyExists"
+}
+
+// IsConflict reports whether err was caused by a write that conflicted with
+// a concurrent change, typically because the object's resourceVersion was
+// stale.
+func IsConflict(err error) bool {
+	return statusCode(err) == http.StatusConflict && statusReason(err) != "AlreadyExists"
+}
+
+// IsForbidden reports whether err was caused by a 403 Forbidden response.
+func IsForbidden(err error) bool {
+	return statusCode(err) == http.StatusForbidden
+}
+
+// isConflict reports whether err is an AlreadyExists or Conflict response.
+func isConflict(err error) bool {
+	return statusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,387 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	eventsPath          = apiPrefix + "/namespaces/%s/events"
+	allEventsPath       = apiPrefix + "/events"
+	watchEventsPath     = apiPrefix + "/watch/namespaces/%s/events"
+	watchAllEventsPath  = apiPrefix + "/watch/events"
+	eventDedupCacheSize = 10000
+
+	// podEventsTimeout bounds fetching a pod's events to explain an error,
+	// which may have been caused by the context of the failed call being
+	// done.
+	podEventsTimeout = 5 * time.Second
+)
+
+// EventSeverity filters events by their type.
+type EventSeverity int
+
+const (
+	// SeverityNormal includes every event.
+	SeverityNormal EventSeverity = iota
+	// SeverityWarning includes only Warning events.
+	SeverityWarning
+)
+
+// EventResult wraps an api.Event received by WatchEvents.
+type EventResult struct {
+	Event *api.Event
+	// Type is the event's type, Normal or Warning.
+	Type string
+	Err  error
+}
+
+type EventResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+// KubeResourcesURL returns the events collection of the namespace, or of all
+// namespaces if Namespace is empty.
+func (e *EventResource) KubeResourcesURL() string {
+	if e.Namespace == "" {
+		return e.Host + allEventsPath
+	}
+	return e.Host + fmt.Sprintf(eventsPath, e.Namespace)
+}
+
+func (e *EventResource) KubeResourceNamespace() string {
+	return e.Namespace
+}
+
+func (e *EventResource) KubeResourceLabel() string {
+	return e.Label
+}
+
+// EventList lists the events in namespace (all namespaces if empty) whose
+// labels match label.
+func (c *Client) EventList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.Event, error) {
+	events, _, err := c.eventList(ctx, namespace, label, firstListOptions(opts))
+	return events, err
+}
+
+// eventList lists events along with their types, which the api package
+// predates.
+func (c *Client) eventList(ctx context.Context, namespace, label string, opts ListOptions) ([]api.Event, []string, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &EventResource{c.Host, namespace, label}, opts, c.Client)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var eventList api.EventList
+	if err := json.Unmarshal(apiResult, &eventList); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode event resources: %v", err)
+	}
+	var typeList struct {
+		Items []eventType `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &typeList); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode event resources: %v", err)
+	}
+	types := make([]string, len(typeList.Items))
+	for i, item := range typeList.Items {
+		types[i] = item.Type
+	}
+	return eventList.Items, types, nil
+}
+
+// ObjectEvents lists the events about the object of the given kind and name
+// in namespace, oldest first.
+func (c *Client) ObjectEvents(ctx context.Context, namespace, kind, name string) ([]api.Event, error) {
+	events, _, err := c.objectEvents(ctx, namespace, kind, name)
+	return events, err
+}
+
+func (c *Client) objectEvents(ctx context.Context, namespace, kind, name string) ([]api.Event, []string, error) {
+	opts := ListOptions{FieldSelector: "involvedObject.kind=" + kind + ",involvedObject.name=" + name}
+	events, types, err := c.eventList(ctx, namespace, "", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(eventsByTime{events, types})
+	return events, types, nil
+}
+
+// eventsByTime sorts events, along with their types, by when they were
+// last seen.
+type eventsByTime struct {
+	events []api.Event
+	types  []string
+}
+
+func (s eventsByTime) Len() int { return len(s.events) }
+
+func (s eventsByTime) Less(i, j int) bool {
+	return s.events[i].LastTimestamp.Before(s.events[j].LastTimestamp.Time)
+}
+
+func (s eventsByTime) Swap(i, j int) {
+	s.events[i], s.events[j] = s.events[j], s.events[i]
+	s.types[i], s.types[j] = s.types[j], s.types[i]
+}
+
+// CreateEvent records an event of eventType, Normal or Warning, about the
+// involved object, in the object's namespace. The event is named after the
+// object, with a suffix the apiserver generates.
+func (c *Client) CreateEvent(ctx context.Context, involved api.ObjectReference, eventType, reason, message string) (*api.Event, error) {
+	namespace, err := c.resolveNamespace("", involved.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	now := api.Time{Time: c.clock().Now()}
+	event := struct {
+		*api.Event
+		Type string `json:"type,omitempty"`
+	}{
+		Event: &api.Event{
+			ObjectMeta:     api.ObjectMeta{GenerateName: involved.Name + ".", Namespace: namespace},
+			InvolvedObject: involved,
+			Reason:         reason,
+			Message:        message,
+			FirstTimestamp: now,
+			LastTimestamp:  now,
+			Count:          1,
+		},
+		Type: eventType,
+	}
+	var eventJSON bytes.Buffer
+	if err := json.NewEncoder(&eventJSON).Encode(event); err != nil {
+		return nil, fmt.Errorf("failed to encode event in json: %v", err)
+	}
+
+	apiResult, err := CreateKubeResource(ctx, &EventResource{c.Host, namespace, ""}, eventJSON, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var eventResult api.Event
+	if err := json.Unmarshal(apiResult, &eventResult); err != nil {
+		return nil, fmt.Errorf("failed to decode event json: %v", err)
+	}
+	return &eventResult, nil
+}
+
+// PodEventsError is an error waiting on a pod, along with the pod's Warning
+// events, such as failures to pull its image, which often explain it.
+type PodEventsError struct {
+	Err error
+	// Warnings are the pod's Warning events, oldest first.
+	Warnings []api.Event
+}
+
+func (e *PodEventsError) Error() string {
+	if len(e.Warnings) == 0 {
+		return e.Err.Error()
+	}
+	warnings := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		warnings[i] = w.Reason + ": " + w.Message
+	}
+	return fmt.Sprintf("%v (pod events: %s)", e.Err, strings.Join(warnings, "; "))
+}
+
+func (e *PodEventsError) Unwrap() error {
+	return e.Err
+}
+
+// withPodEvents wraps err in a *PodEventsError carrying the pod's Warning
+// events. If the caller canceled the wait, or the events cannot be fetched,
+// err is returned as it is.
+func (c *Client) withPodEvents(namespace, podName string, err error) error {
+	if err == context.Canceled {
+		return err
+	}
+	ctx, cancel := withTimeout(context.Background(), c.clock(), podEventsTimeout)
+	defer cancel()
+	events, types, listErr := c.objectEvents(ctx, namespace, "Pod", podName)
+	if listErr != nil {
+		return err
+	}
+	var warnings []api.Event
+	for i, event := range events {
+		if types[i] == "Warning" {
+			warnings = append(warnings, event)
+		}
+	}
+	return &PodEventsError{Err: err, Warnings: warnings}
+}
+
+type watchEventStatus struct {
+	// The type of watch update contained in the message
+	Type string `json:"type"`
+	// Event details, or an api.Status for ERROR updates
+	Object json.RawMessage `json:"object"`
+}
+
+// eventType holds the type field of an event, which the api package
+// predates.
+type eventType struct {
+	Type string `json:"type"`
+}
+
+// eventDedup suppresses repeats of an event, which the apiserver reports by
+// updating the original event with a higher count.
+type eventDedup struct {
+	seen map[string]int
+}
+
+// first reports whether e has not been seen before, and records it.
+func (d *eventDedup) first(e *api.Event) bool {
+	key := fmt.Sprintf("%s/%s/%s/%s/%s", e.InvolvedObject.Kind, e.InvolvedObject.Namespace, e.InvolvedObject.Name, e.Reason, e.Message)
+	if _, ok := d.seen[key]; ok {
+		d.seen[key] = e.Count
+		return false
+	}
+	if len(d.seen) >= eventDedupCacheSize {
+		d.seen = make(map[string]int)
+	}
+	d.seen[key] = e.Count
+	return true
+}
+
+// WatchEvents watches the events in namespace (all namespaces if empty)
+// matching fieldSelector, e.g. "involvedObject.name=mypod", much like
+// `kubectl get events -w`. Existing events are sent first. An event that
+// repeats is only sent the first time it is seen, and events less severe
+// than severity are dropped.
+// The provided context must be canceled or timed out to stop the watch.
+// When the apiserver closes the watch, as it does after a timeout, the
+// watch resumes from the last resourceVersion seen.
+// If the watch expires (410 Gone) it resumes from the current state and
+// sends an EventResult with Type WatchResyncNeeded; events in between may be
+// missed. If any other error occurs, it is sent on the returned channel and
+// the channel is closed.
+func (c *Client) WatchEvents(ctx context.Context, namespace, fieldSelector string, severity EventSeverity) (<-chan EventResult, error) {
+	return c.watchEventsFrom(ctx, namespace, fieldSelector, "", severity)
+}
+
+// watchEventsFrom is WatchEvents starting after resourceVersion, so only
+// events recorded since are sent; an empty resourceVersion sends the
+// existing events first.
+func (c *Client) watchEventsFrom(ctx context.Context, namespace, fieldSelector, resourceVersion string, severity EventSeverity) (<-chan EventResult, error) {
+	eventChan := make(chan EventResult)
+
+	go func() {
+		defer close(eventChan)
+		dedup := &eventDedup{seen: make(map[string]int)}
+		backoff := watchBackoff{clock: c.clock()}
+		for {
+			err := c.watchEvents(ctx, namespace, fieldSelector, &resourceVersion, severity, dedup, eventChan, &backoff)
+			if err == ErrWatchClosed {
+				continue
+			}
+			if err != ErrWatchGone {
+				eventChan <- EventResult{Err: err}
+				return
+			}
+			if err := backoff.wait(ctx); err != nil {
+				eventChan <- EventResult{Err: err}
+				return
+			}
+			apiResult, err := ListKubeResources(ctx, &EventResource{c.Host, namespace, ""}, c.Client)
+			if err != nil {
+				eventChan <- EventResult{Err: fmt.Errorf("failed to relist events after 410 Gone: %w", err)}
+				return
+			}
+			var eventList api.EventList
+			if err := json.Unmarshal(apiResult, &eventList); err != nil {
+				eventChan <- EventResult{Err: fmt.Errorf("failed to decode event resources: %v", err)}
+				return
+			}
+			eventChan <- EventResult{Type: WatchResyncNeeded}
+			resourceVersion = eventList.ResourceVersion
+		}
+	}()
+	return eventChan, nil
+}
+
+// watchEvents runs a single watch connection, sending its events on
+// eventChan and advancing resourceVersion past them, until the connection
+// fails. It returns ErrWatchGone if resourceVersion has expired and
+// ErrWatchClosed if the apiserver ended the watch.
+func (c *Client) watchEvents(ctx context.Context, namespace, fieldSelector string, resourceVersion *string, severity EventSeverity, dedup *eventDedup, eventChan chan<- EventResult, backoff *watchBackoff) error {
+	values := url.Values{}
+	if fieldSelector != "" {
+		values.Set("fieldSelector", fieldSelector)
+	}
+	if *resourceVersion != "" {
+		values.Set("resourceVersion", *resourceVersion)
+	}
+	getURL := c.Host + watchAllEventsPath
+	if namespace != "" {
+		getURL = c.Host + fmt.Sprintf(watchEventsPath, namespace)
+	}
+	getURL += "?" + values.Encode()
+	req, err := http.NewRequest("GET", getURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: GET %q : %v", getURL, err)
+	}
+	res, err := doRequest(ctx, c.Client, req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: GET %q: %v", getURL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusGone {
+		return ErrWatchGone
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return newStatusError("GET", getURL, res.StatusCode, body)
+	}
+
+	stop := closeOnDone(ctx, res.Body)
+	defer stop()
+
+	decoder := json.NewDecoder(res.Body)
+	for {
+		var wes watchEventStatus
+		err := decoder.Decode(&wes)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == io.EOF {
+			return ErrWatchClosed
+		}
+		if err != nil {
+			return fmt.Errorf("error reading streaming response body: %v", err)
+		}
+		if wes.Type == "ERROR" {
+			return watchStatusError(wes.Object)
+		}
+		var event api.Event
+		var et eventType
+		if err := json.Unmarshal(wes.Object, &event); err != nil {
+			return fmt.Errorf("failed to decode watch event: %v", err)
+		}
+		if err := json.Unmarshal(wes.Object, &et); err != nil {
+			return fmt.Errorf("failed to decode watch event: %v", err)
+		}
+		backoff.reset()
+		if event.ResourceVersion != "" {
+			*resourceVersion = event.ResourceVersion
+		}
+
+		if wes.Type == "DELETED" || !dedup.first(&event) {
+			continue
+		}
+		if severity == SeverityWarning && et.Type != "Warning" {
+			continue
+		}
+		eventChan <- EventResult{Event: &event, Type: et.Type}
+	}
+}
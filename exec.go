@@ -0,0 +1,175 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// Channels of the apiserver's streaming protocols; the first byte of each
+// WebSocket message names the stream it belongs to.
+const (
+	execStdin  = 0
+	execStdout = 1
+	execStderr = 2
+	execError  = 3
+	// execClose, in v5.channel.k8s.io, closes the stream named by the
+	// following byte.
+	execClose = 255
+)
+
+// execProtocols are the streaming protocols Exec offers, preferred first.
+var execProtocols = []string{"v5.channel.k8s.io", "v4.channel.k8s.io"}
+
+// ExecOptions are the streams of a command run by Exec. Nil streams are not
+// attached.
+type ExecOptions struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY allocates a terminal for the command. Its stderr is then merged
+	// into Stdout.
+	TTY bool
+}
+
+// ExecExitError is returned by Exec for a command that exited with a
+// non-zero status.
+type ExecExitError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExecExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d: %s", e.Code, e.Message)
+}
+
+// Exec runs cmd in container of the pod, like kubectl exec, streaming the
+// command's stdin, stdout, and stderr from and to opts. It blocks until the
+// command exits, returning an *ExecExitError if it exits non-zero. An
+// empty container selects the pod's only container.
+// Streams are carried over a WebSocket. When Stdin reaches EOF the command's
+// stdin is closed if the apiserver speaks v5.channel.k8s.io; older
+// apiservers leave it open until the command exits.
+func (c *Client) Exec(ctx context.Context, namespace, podName, container string, cmd []string, opts ExecOptions) error {
+	if len(cmd) == 0 {
+		return fmt.Errorf("exec in pod %s requires a command", podName)
+	}
+	values := url.Values{}
+	for _, arg := range cmd {
+		values.Add("command", arg)
+	}
+	if container != "" {
+		values.Set("container", container)
+	}
+	if opts.Stdin != nil {
+		values.Set("stdin", "true")
+	}
+	if opts.Stdout != nil {
+		values.Set("stdout", "true")
+	}
+	if opts.Stderr != nil && !opts.TTY {
+		values.Set("stderr", "true")
+	}
+	if opts.TTY {
+		values.Set("tty", "true")
+	}
+	execURL := c.podURL(namespace, podName) + "/exec?" + values.Encode()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	conn, err := dialWebSocket(ctx, c.Client, execURL, execProtocols)
+	if err != nil {
+		return fmt.Errorf("Exec failed: %w", err)
+	}
+	defer conn.Close()
+	stop := closeOnDone(ctx, conn)
+	defer stop()
+
+	if opts.Stdin != nil {
+		go sendStdin(conn, opts.Stdin)
+	}
+
+	var status []byte
+	for {
+		message, err := conn.readMessage()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading exec stream: %v", err)
+		}
+		if len(message) < 2 {
+			// Each stream opens with an empty message.
+			continue
+		}
+		var w io.Writer
+		switch message[0] {
+		case execStdout:
+			w = opts.Stdout
+		case execStderr:
+			w = opts.Stderr
+		case execError:
+			status = append(status, message[1:]...)
+		}
+		if w != nil {
+			if _, err := w.Write(message[1:]); err != nil {
+				return fmt.Errorf("failed to write exec output: %v", err)
+			}
+		}
+	}
+	return execStatusError(status)
+}
+
+// sendStdin copies stdin to the command until either side fails.
+func sendStdin(conn *wsConn, stdin io.Reader) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := stdin.Read(buf)
+		if n > 0 {
+			if werr := conn.writeMessage(append([]byte{execStdin}, buf[:n]...)); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if err == io.EOF && conn.protocol == execProtocols[0] {
+				conn.writeMessage([]byte{execClose, execStdin})
+			}
+			return
+		}
+	}
+}
+
+// execStatusError converts the api.Status sent on the error stream when a
+// command finishes into an error.
+func execStatusError(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var status api.Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		// Older apiservers send a plain message.
+		return fmt.Errorf("exec failed: %s", data)
+	}
+	if status.Status == "Success" {
+		return nil
+	}
+	if status.Reason == "NonZeroExitCode" && status.Details != nil {
+		for _, cause := range status.Details.Causes {
+			if cause.Type != "ExitCode" {
+				continue
+			}
+			var code int
+			if _, err := fmt.Sscan(cause.Message, &code); err == nil {
+				return &ExecExitError{Code: code, Message: status.Message}
+			}
+		}
+	}
+	return fmt.Errorf("exec failed: %s", status.Message)
+}
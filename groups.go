@@ -0,0 +1,49 @@
+package kubeclient
+
+import "fmt"
+
+// groupVersionPrefix returns the path prefix of an API group version, e.g.
+// "/apis/apps/v1". The core group, named "", is served under "/api/v1".
+func groupVersionPrefix(group, version string) string {
+	if group == "" {
+		return "/api/" + version
+	}
+	return apisPath + "/" + group + "/" + version
+}
+
+// APIResource is a KubeResource for a resource in any API group, for use
+// with the generic resource functions when the client has no dedicated
+// methods for it. For example, CronJobs in a namespace are
+// &APIResource{c.Host, "batch", "v1", "cronjobs", namespace, ""}.
+type APIResource struct {
+	Host    string
+	Group   string
+	Version string
+	// Resource is the plural, lower case name of the resource.
+	Resource string
+	// Namespace is empty for cluster-scoped resources.
+	Namespace string
+	Label     string
+}
+
+func (r *APIResource) KubeResourcesURL() string {
+	prefix := r.Host + groupVersionPrefix(r.Group, r.Version)
+	if r.Namespace == "" {
+		return prefix + "/" + r.Resource
+	}
+	return prefix + fmt.Sprintf("/namespaces/%s/%s", r.Namespace, r.Resource)
+}
+
+func (r *APIResource) KubeResourceNamespace() string {
+	return r.Namespace
+}
+
+func (r *APIResource) KubeResourceLabel() string {
+	return r.Label
+}
+
+// ObjectURL returns the URL of the named object, for use with
+// GetKubeResource, UpdateKubeResource, and DeleteKubeResource.
+func (r *APIResource) ObjectURL(name string) string {
+	return r.KubeResourcesURL() + "/" + name
+}
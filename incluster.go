@@ -0,0 +1,104 @@
+package kubeclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// tokenRecheckInterval is how often the service account token file is
+	// checked for rotation.
+	tokenRecheckInterval = time.Minute
+)
+
+// InClusterClient returns a client for the cluster the program runs in,
+// authenticated as the pod's service account. It reads the service
+// account's token, CA certificate, and namespace from the files Kubernetes
+// mounts into the pod, and the apiserver's address from the environment.
+// The token file is re-read when it changes, as projected tokens are
+// rotated. The client's Namespace is the pod's namespace.
+func InClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be set; is this running in a pod?")
+	}
+	caData, err := dataFromFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA: %v", err)
+	}
+	token := &tokenFile{path: serviceAccountDir + "/token"}
+	if _, err := token.get(); err != nil {
+		return nil, err
+	}
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    rootCertPool(caData),
+		},
+	}
+	client := newClient(apiServerURL(host, port), &authTransport{provider: bearerTokenAuth{token: token.get, refresh: token.expire}, rt: tr})
+	if namespace, err := dataFromFile(serviceAccountDir + "/namespace"); err == nil {
+		client.Namespace = string(bytes.TrimSpace(namespace))
+	}
+	return client, nil
+}
+
+// tokenFile caches a bearer token read from a file, re-reading it when the
+// file's modification time changes.
+type tokenFile struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	checked time.Time
+}
+
+func (f *tokenFile) get() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token != "" && time.Since(f.checked) < tokenRecheckInterval {
+		return f.token, nil
+	}
+	info, err := os.Stat(f.path)
+	if err != nil {
+		if f.token != "" {
+			// Keep using the last token; the file may be mid-rotation.
+			return f.token, nil
+		}
+		return "", fmt.Errorf("failed to read service account token: %v", err)
+	}
+	f.checked = time.Now()
+	if f.token != "" && info.ModTime().Equal(f.modTime) {
+		return f.token, nil
+	}
+	data, err := dataFromFile(f.path)
+	if err != nil {
+		if f.token != "" {
+			return f.token, nil
+		}
+		return "", fmt.Errorf("failed to read service account token: %v", err)
+	}
+	f.token = string(bytes.TrimSpace(data))
+	f.modTime = info.ModTime()
+	return f.token, nil
+}
+
+// expire re-reads the token file now, and reports whether the token
+// changed.
+func (f *tokenFile) expire() bool {
+	f.mu.Lock()
+	old := f.token
+	f.checked = time.Time{}
+	f.mu.Unlock()
+	token, err := f.get()
+	return err == nil && token != old
+}
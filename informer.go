@@ -0,0 +1,305 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// PodHandler receives the changes a PodInformer sees. Nil funcs are
+// skipped. Handlers are called one at a time, in order, from the goroutine
+// running the informer, and must not modify the pods they are given.
+type PodHandler struct {
+	OnAdd    func(pod *api.Pod)
+	OnUpdate func(old, new *api.Pod)
+	OnDelete func(pod *api.Pod)
+}
+
+// IndexFunc returns the values a pod is indexed under, e.g. its node name.
+type IndexFunc func(pod *api.Pod) []string
+
+// PodInformer keeps an in-memory cache of the pods in a namespace matching
+// a label, so callers can read them as often as they like without going to
+// the apiserver. It lists the pods, then watches them, resuming the watch
+// when the apiserver closes it and re-listing when it expires. Changes are
+// passed to the registered handlers, including deletions discovered by a
+// re-list.
+//
+// Handlers and indexes must be added before Run is called.
+type PodInformer struct {
+	client    *Client
+	namespace string
+	label     string
+
+	// OnError, if set, is called with list and watch failures, which are
+	// otherwise retried silently.
+	OnError func(error)
+
+	handlers []PodHandler
+	indexers map[string]IndexFunc
+
+	mu      sync.RWMutex
+	pods    map[string]*api.Pod
+	indices map[string]map[string]map[string]bool
+	synced  chan struct{}
+}
+
+// NewPodInformer returns an informer for the pods in namespace matching
+// label. It does nothing until Run is called.
+func NewPodInformer(client *Client, namespace, label string) *PodInformer {
+	return &PodInformer{
+		client:    client,
+		namespace: namespace,
+		label:     label,
+		indexers:  make(map[string]IndexFunc),
+		pods:      make(map[string]*api.Pod),
+		indices:   make(map[string]map[string]map[string]bool),
+		synced:    make(chan struct{}),
+	}
+}
+
+// AddHandler registers h to be called with every change.
+func (inf *PodInformer) AddHandler(h PodHandler) {
+	inf.handlers = append(inf.handlers, h)
+}
+
+// AddIndex maintains an index named name, under the values fn returns for
+// each pod, for use with ByIndex.
+func (inf *PodInformer) AddIndex(name string, fn IndexFunc) {
+	inf.indexers[name] = fn
+	inf.indices[name] = make(map[string]map[string]bool)
+}
+
+// Run keeps the cache up to date until ctx is done, and returns ctx.Err().
+func (inf *PodInformer) Run(ctx context.Context) error {
+	resource := &PodResource{inf.client.Host, inf.namespace, inf.label}
+	backoff := watchBackoff{clock: inf.client.clock()}
+	resourceVersion := ""
+	for {
+		var err error
+		if resourceVersion == "" {
+			resourceVersion, err = inf.list(ctx, resource)
+		}
+		if err == nil {
+			err = watchKubeResources(ctx, resource, resourceVersion, inf.client.Client, func(eventType string, object json.RawMessage) error {
+				var pod api.Pod
+				if err := json.Unmarshal(object, &pod); err != nil {
+					return fmt.Errorf("failed to decode watch pod status: %v", err)
+				}
+				backoff.reset()
+				resourceVersion = pod.ResourceVersion
+				if eventType == "DELETED" {
+					inf.delete(podKey(&pod))
+				} else {
+					inf.upsert(&pod)
+				}
+				return nil
+			})
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == ErrWatchClosed {
+			// Resume where the watch left off.
+			continue
+		}
+		if err != ErrWatchGone {
+			inf.error(err)
+		}
+		resourceVersion = ""
+		if err := backoff.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// list replaces the cache with the current pods, reporting the differences
+// to the handlers, and returns the list's resourceVersion.
+func (inf *PodInformer) list(ctx context.Context, resource KubeResource) (string, error) {
+	apiResult, err := ListKubeResources(ctx, resource, inf.client.Client)
+	if err != nil {
+		return "", fmt.Errorf("Resource List failed: %w", err)
+	}
+	var podList api.PodList
+	if err := json.Unmarshal(apiResult, &podList); err != nil {
+		return "", fmt.Errorf("failed to decode pod resources: %v", err)
+	}
+
+	listed := make(map[string]bool, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		listed[podKey(pod)] = true
+		inf.upsert(pod)
+	}
+	inf.mu.RLock()
+	var gone []string
+	for key := range inf.pods {
+		if !listed[key] {
+			gone = append(gone, key)
+		}
+	}
+	inf.mu.RUnlock()
+	for _, key := range gone {
+		inf.delete(key)
+	}
+
+	select {
+	case <-inf.synced:
+	default:
+		close(inf.synced)
+	}
+	return podList.ResourceVersion, nil
+}
+
+// upsert caches pod, calling OnAdd or, if it changed, OnUpdate.
+func (inf *PodInformer) upsert(pod *api.Pod) {
+	key := podKey(pod)
+	inf.mu.Lock()
+	old := inf.pods[key]
+	if old != nil && old.ResourceVersion == pod.ResourceVersion {
+		inf.mu.Unlock()
+		return
+	}
+	inf.unindex(key, old)
+	inf.pods[key] = pod
+	inf.index(key, pod)
+	inf.mu.Unlock()
+
+	for _, h := range inf.handlers {
+		switch {
+		case old == nil && h.OnAdd != nil:
+			h.OnAdd(pod)
+		case old != nil && h.OnUpdate != nil:
+			h.OnUpdate(old, pod)
+		}
+	}
+}
+
+// delete removes the pod with key from the cache, calling OnDelete.
+func (inf *PodInformer) delete(key string) {
+	inf.mu.Lock()
+	old := inf.pods[key]
+	if old == nil {
+		inf.mu.Unlock()
+		return
+	}
+	inf.unindex(key, old)
+	delete(inf.pods, key)
+	inf.mu.Unlock()
+
+	for _, h := range inf.handlers {
+		if h.OnDelete != nil {
+			h.OnDelete(old)
+		}
+	}
+}
+
+// index and unindex must be called with inf.mu held.
+func (inf *PodInformer) index(key string, pod *api.Pod) {
+	for name, fn := range inf.indexers {
+		for _, value := range fn(pod) {
+			keys := inf.indices[name][value]
+			if keys == nil {
+				keys = make(map[string]bool)
+				inf.indices[name][value] = keys
+			}
+			keys[key] = true
+		}
+	}
+}
+
+func (inf *PodInformer) unindex(key string, pod *api.Pod) {
+	if pod == nil {
+		return
+	}
+	for name, fn := range inf.indexers {
+		for _, value := range fn(pod) {
+			delete(inf.indices[name][value], key)
+			if len(inf.indices[name][value]) == 0 {
+				delete(inf.indices[name], value)
+			}
+		}
+	}
+}
+
+// HasSynced reports whether the initial list has completed.
+func (inf *PodInformer) HasSynced() bool {
+	select {
+	case <-inf.synced:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForSync blocks until the initial list has completed or ctx is done.
+func (inf *PodInformer) WaitForSync(ctx context.Context) error {
+	select {
+	case <-inf.synced:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Get returns the cached pod. The pod is shared and must not be modified.
+func (inf *PodInformer) Get(namespace, name string) (*api.Pod, bool) {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	pod, ok := inf.pods[namespace+"/"+name]
+	return pod, ok
+}
+
+// List returns every cached pod, sorted by namespace and name. The pods
+// are shared and must not be modified.
+func (inf *PodInformer) List() []*api.Pod {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	keys := make([]string, 0, len(inf.pods))
+	for key := range inf.pods {
+		keys = append(keys, key)
+	}
+	return inf.sortedPods(keys)
+}
+
+// ByIndex returns the cached pods indexed under value in the named index,
+// sorted by namespace and name. The pods are shared and must not be
+// modified.
+func (inf *PodInformer) ByIndex(index, value string) ([]*api.Pod, error) {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	values, ok := inf.indices[index]
+	if !ok {
+		return nil, fmt.Errorf("no index named %q", index)
+	}
+	keys := make([]string, 0, len(values[value]))
+	for key := range values[value] {
+		keys = append(keys, key)
+	}
+	return inf.sortedPods(keys), nil
+}
+
+// sortedPods must be called with inf.mu held.
+func (inf *PodInformer) sortedPods(keys []string) []*api.Pod {
+	sort.Strings(keys)
+	pods := make([]*api.Pod, len(keys))
+	for i, key := range keys {
+		pods[i] = inf.pods[key]
+	}
+	return pods
+}
+
+func (inf *PodInformer) error(err error) {
+	if inf.OnError != nil {
+		inf.OnError(err)
+	}
+}
+
+// podKey returns the cache key of pod, "namespace/name".
+func podKey(pod *api.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
@@ -0,0 +1,93 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// inventoryResources lists the namespaced resources Inventory covers, by
+// resource name. It is the one table of the namespaced resources this client
+// supports: AwaitStable and ListByDeploy derive theirs from it, so a kind
+// added here is covered by all of them.
+var inventoryResources = map[string]APIResource{
+	"configmaps":             {Version: "v1", Resource: "configmaps"},
+	"deployments":            {Group: "apps", Version: "v1", Resource: "deployments"},
+	"endpoints":              {Version: "v1", Resource: "endpoints"},
+	"jobs":                   {Group: "batch", Version: "v1", Resource: "jobs"},
+	"persistentvolumeclaims": {Version: "v1", Resource: "persistentvolumeclaims"},
+	"pods":                   {Version: "v1", Resource: "pods"},
+	"replicationcontrollers": {Version: "v1", Resource: "replicationcontrollers"},
+	"rolebindings":           {Group: rbacGroup, Version: "v1", Resource: "rolebindings"},
+	"roles":                  {Group: rbacGroup, Version: "v1", Resource: "roles"},
+	"secrets":                {Version: "v1", Resource: "secrets"},
+	"serviceaccounts":        {Version: "v1", Resource: "serviceaccounts"},
+	"services":               {Version: "v1", Resource: "services"},
+	"statefulsets":           {Group: "apps", Version: "v1", Resource: "statefulsets"},
+}
+
+// inventoryResource returns the KubeResource of the named inventory
+// resource in namespace on host, or in every namespace if namespace is "".
+func inventoryResource(name, host, namespace string) *APIResource {
+	res := inventoryResources[name]
+	res.Host = host
+	res.Namespace = namespace
+	return &res
+}
+
+// Inventory maps resource names, such as "pods", to the sorted names of the
+// objects of that resource.
+type Inventory map[string][]string
+
+// Counts returns the number of objects of each resource.
+func (inv Inventory) Counts() map[string]int {
+	counts := make(map[string]int, len(inv))
+	for resource, names := range inv {
+		counts[resource] = len(names)
+	}
+	return counts
+}
+
+// itemNames holds just the names of the items in a list.
+type itemNames struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	} `json:"items"`
+}
+
+// Inventory lists every resource this client supports in namespace and
+// returns the names of the objects found, grouped by resource. If ctx is
+// done first, the resources listed so far are returned with an *ErrPartial.
+func (c *Client) Inventory(ctx context.Context, namespace string) (Inventory, error) {
+	resources := make([]string, 0, len(inventoryResources))
+	for resource := range inventoryResources {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	inv := make(Inventory, len(inventoryResources))
+	for i, resource := range resources {
+		apiResult, err := ListKubeResources(ctx, inventoryResource(resource, c.Host, namespace), c.Client)
+		if err != nil {
+			if ctx.Err() != nil {
+				return inv, &ErrPartial{Missing: resources[i:], Err: ctx.Err()}
+			}
+			return nil, fmt.Errorf("Resource List failed for %s: %w", resource, err)
+		}
+		var list itemNames
+		if err := json.Unmarshal(apiResult, &list); err != nil {
+			return nil, fmt.Errorf("failed to decode %s resources: %v", resource, err)
+		}
+		names := make([]string, len(list.Items))
+		for i, item := range list.Items {
+			names[i] = item.Metadata.Name
+		}
+		sort.Strings(names)
+		inv[resource] = names
+	}
+	return inv, nil
+}
@@ -0,0 +1,252 @@
+package kubeclient
+
+import (
+	"container/heap"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// JobSubmission is a job to run through a JobQueue.
+type JobSubmission struct {
+	// Tenant is the party the job is run for; each tenant's running jobs
+	// are limited separately.
+	Tenant string
+	// Priority orders the pending jobs; higher runs first, and jobs of
+	// equal priority run in submission order.
+	Priority int
+	Job      *Job
+}
+
+// JobTicket tracks a submitted job.
+type JobTicket struct {
+	done chan struct{}
+	job  *Job
+	err  error
+}
+
+// Done is closed once the job has finished, failed to start, or been
+// abandoned because the queue stopped.
+func (t *JobTicket) Done() <-chan struct{} {
+	return t.done
+}
+
+// Result returns the finished job, and an error if it failed or could not
+// be created. It must only be called after Done is closed.
+func (t *JobTicket) Result() (*Job, error) {
+	return t.job, t.err
+}
+
+// JobQueue runs submitted jobs in priority order while limiting how many
+// each tenant has running at once, tracking each job until it finishes. The
+// limits are enforced by this client alone, so every submitter must share
+// the same queue.
+type JobQueue struct {
+	client *Client
+	// DefaultLimit is the number of jobs a tenant without its own limit
+	// may have running; zero or less means no limit.
+	DefaultLimit int
+
+	mu      sync.Mutex
+	wake    chan struct{}
+	limits  map[string]int
+	running map[string]int
+	pending jobHeap
+	seq     int
+	// stopped is the error Run returned with, failing jobs submitted
+	// after it.
+	stopped error
+}
+
+// NewJobQueue returns a queue that creates jobs through client, allowing
+// each tenant defaultLimit running jobs. Jobs without a namespace are
+// created in client.Namespace.
+func NewJobQueue(client *Client, defaultLimit int) *JobQueue {
+	return &JobQueue{
+		client:       client,
+		DefaultLimit: defaultLimit,
+		wake:         make(chan struct{}, 1),
+		limits:       make(map[string]int),
+		running:      make(map[string]int),
+	}
+}
+
+// SetTenantLimit sets the number of jobs tenant may have running at once,
+// overriding DefaultLimit.
+func (q *JobQueue) SetTenantLimit(tenant string, limit int) {
+	q.mu.Lock()
+	q.limits[tenant] = limit
+	q.mu.Unlock()
+	q.signal()
+}
+
+// Submit queues a job and returns a ticket for it. The job is created once
+// Run picks it. If Run has already returned, the ticket is failed at once
+// with the error Run returned.
+func (q *JobQueue) Submit(sub JobSubmission) *JobTicket {
+	t := &JobTicket{done: make(chan struct{})}
+	q.mu.Lock()
+	if q.stopped != nil {
+		err := q.stopped
+		q.mu.Unlock()
+		t.finish(nil, err)
+		return t
+	}
+	q.seq++
+	heap.Push(&q.pending, &queuedJob{sub: sub, ticket: t, seq: q.seq})
+	q.mu.Unlock()
+	q.signal()
+	return t
+}
+
+// Pending returns the number of jobs waiting to be created.
+func (q *JobQueue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending.Len()
+}
+
+// Running returns the number of jobs of tenant that have been created and
+// not yet finished.
+func (q *JobQueue) Running(tenant string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running[tenant]
+}
+
+// Run creates queued jobs as their tenants' limits allow and waits for them
+// to finish, until ctx is done. It then fails the tickets of the jobs still
+// pending with ctx.Err(); jobs already created keep running in the cluster
+// but their tickets are failed too, as they are no longer tracked, as are
+// the tickets of jobs submitted later. Run returns ctx.Err().
+func (q *JobQueue) Run(ctx context.Context) error {
+	q.mu.Lock()
+	q.stopped = nil
+	q.mu.Unlock()
+	var wg sync.WaitGroup
+	for {
+		for {
+			qj := q.next()
+			if qj == nil {
+				break
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				q.run(ctx, qj)
+			}()
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			q.mu.Lock()
+			pending := q.pending
+			q.pending = nil
+			q.stopped = ctx.Err()
+			q.mu.Unlock()
+			for _, qj := range pending {
+				qj.ticket.finish(nil, ctx.Err())
+			}
+			return ctx.Err()
+		case <-q.wake:
+		}
+	}
+}
+
+// next removes and returns the highest priority pending job whose tenant
+// is below its limit, counting it as running, or returns nil.
+func (q *JobQueue) next() *queuedJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var skipped []*queuedJob
+	defer func() {
+		for _, qj := range skipped {
+			heap.Push(&q.pending, qj)
+		}
+	}()
+	for q.pending.Len() > 0 {
+		qj := heap.Pop(&q.pending).(*queuedJob)
+		tenant := qj.sub.Tenant
+		limit, ok := q.limits[tenant]
+		if !ok {
+			limit = q.DefaultLimit
+		}
+		if limit > 0 && q.running[tenant] >= limit {
+			skipped = append(skipped, qj)
+			continue
+		}
+		q.running[tenant]++
+		return qj
+	}
+	return nil
+}
+
+// run creates the job and waits for it to finish. The tenant's slot is
+// only released once the job has finished, is gone, or ctx is done, so
+// errors waiting on a job that is still running are retried.
+func (q *JobQueue) run(ctx context.Context, qj *queuedJob) {
+	defer func() {
+		q.mu.Lock()
+		q.running[qj.sub.Tenant]--
+		q.mu.Unlock()
+		q.signal()
+	}()
+	job, err := q.client.CreateJob(ctx, qj.sub.Job)
+	if err != nil {
+		qj.ticket.finish(nil, err)
+		return
+	}
+	backoff := watchBackoff{clock: q.client.clock()}
+	for {
+		finished, err := q.client.AwaitJobCompletion(ctx, job.Namespace, job.Name)
+		if finished != nil || err == nil || ctx.Err() != nil || IsNotFound(err) {
+			qj.ticket.finish(finished, err)
+			return
+		}
+		if err := backoff.wait(ctx); err != nil {
+			qj.ticket.finish(nil, err)
+			return
+		}
+	}
+}
+
+func (q *JobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (t *JobTicket) finish(job *Job, err error) {
+	t.job, t.err = job, err
+	close(t.done)
+}
+
+type queuedJob struct {
+	sub    JobSubmission
+	ticket *JobTicket
+	seq    int
+}
+
+// jobHeap orders queued jobs by descending priority, then submission.
+type jobHeap []*queuedJob
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].sub.Priority != h[j].sub.Priority {
+		return h[i].sub.Priority > h[j].sub.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *jobHeap) Push(x interface{}) { *h = append(*h, x.(*queuedJob)) }
+
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,202 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	batchPrefix = apisPath + "/batch/v1"
+	jobsPath    = batchPrefix + "/namespaces/%s/jobs"
+	jobPath     = batchPrefix + "/namespaces/%s/jobs/%s"
+)
+
+// Job is a batch/v1 Job, which the api package predates.
+type Job struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Spec           JobSpec   `json:"spec,omitempty"`
+	Status         JobStatus `json:"status,omitempty"`
+}
+
+type JobSpec struct {
+	Parallelism             *int                `json:"parallelism,omitempty"`
+	Completions             *int                `json:"completions,omitempty"`
+	ActiveDeadlineSeconds   *int64              `json:"activeDeadlineSeconds,omitempty"`
+	BackoffLimit            *int                `json:"backoffLimit,omitempty"`
+	Selector                *LabelSelector      `json:"selector,omitempty"`
+	ManualSelector          *bool               `json:"manualSelector,omitempty"`
+	Template                api.PodTemplateSpec `json:"template"`
+	TTLSecondsAfterFinished *int                `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+type JobStatus struct {
+	Conditions     []JobCondition `json:"conditions,omitempty"`
+	StartTime      *api.Time      `json:"startTime,omitempty"`
+	CompletionTime *api.Time      `json:"completionTime,omitempty"`
+	Active         int            `json:"active,omitempty"`
+	Succeeded      int            `json:"succeeded,omitempty"`
+	Failed         int            `json:"failed,omitempty"`
+}
+
+// JobCondition reports that a job is "Complete" or has "Failed".
+type JobCondition struct {
+	Type               string              `json:"type"`
+	Status             api.ConditionStatus `json:"status"`
+	LastTransitionTime api.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string              `json:"reason,omitempty"`
+	Message            string              `json:"message,omitempty"`
+}
+
+type JobResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (j *JobResource) KubeResourcesURL() string {
+	return j.Host + fmt.Sprintf(jobsPath, j.Namespace)
+}
+
+func (j *JobResource) KubeResourceNamespace() string {
+	return j.Namespace
+}
+
+func (j *JobResource) KubeResourceLabel() string {
+	return j.Label
+}
+
+func (c *Client) CreateJob(ctx context.Context, job *Job) (*Job, error) {
+	var jobJSON bytes.Buffer
+	if err := json.NewEncoder(&jobJSON).Encode(job); err != nil {
+		return nil, fmt.Errorf("failed to encode job in json: %v", err)
+	}
+
+	namespace, err := c.resolveNamespace("", job.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &JobResource{c.Host, namespace, ""}, jobJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.jobURL(namespace, job.Name), job)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+
+	var jobResult Job
+	if err := json.Unmarshal(apiResult, &jobResult); err != nil {
+		return nil, fmt.Errorf("failed to decode job resources: %v", err)
+	}
+	return &jobResult, nil
+}
+
+func (c *Client) GetJob(ctx context.Context, namespace, name string) (*Job, error) {
+	apiResult, err := GetKubeResource(ctx, c.jobURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var job Job
+	if err := json.Unmarshal(apiResult, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job json: %v", err)
+	}
+	return &job, nil
+}
+
+// DeleteJob deletes the job along with its pods, which deleting a job
+// otherwise leaves behind, unless opts gives another PropagationPolicy.
+func (c *Client) DeleteJob(ctx context.Context, namespace, jobName string, opts ...DeleteOptions) error {
+	var options DeleteOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.PropagationPolicy == "" {
+		options.PropagationPolicy = DeletePropagationBackground
+	}
+	return deleteKubeResource(ctx, c.jobURL(namespace, jobName), &options, c.Client)
+}
+
+func (c *Client) JobList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]Job, error) {
+	var jobs []Job
+
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &JobResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return jobs, fmt.Errorf("Resource List failed: %w", err)
+	}
+
+	var jobList struct {
+		Items []Job `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &jobList); err != nil {
+		return jobs, fmt.Errorf("failed to decode job resources: %v", err)
+	}
+
+	return jobList.Items, nil
+}
+
+// JobFinished returns the job's Complete or Failed condition, or nil if the
+// job is still running.
+func JobFinished(job *Job) *JobCondition {
+	for i := range job.Status.Conditions {
+		cond := &job.Status.Conditions[i]
+		if (cond.Type == "Complete" || cond.Type == "Failed") && cond.Status == api.ConditionTrue {
+			return cond
+		}
+	}
+	return nil
+}
+
+// AwaitJobCompletion watches the job until it completes or fails, and
+// returns it. If the job failed, the job is returned along with an error
+// giving the reason. If ctx is done first, or the watch fails, an error is
+// returned.
+func (c *Client) AwaitJobCompletion(ctx context.Context, namespace, name string) (*Job, error) {
+	values := url.Values{}
+	values.Set("fieldSelector", "metadata.name="+name)
+	backoff := watchBackoff{clock: c.clock()}
+	for {
+		job, err := c.GetJob(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		var finished *Job
+		if JobFinished(job) != nil {
+			finished = job
+		} else {
+			err = watchKubeResourcesQuery(ctx, &JobResource{c.Host, namespace, ""}, values, job.ResourceVersion, c.Client, func(eventType string, object json.RawMessage) error {
+				var watched Job
+				if err := json.Unmarshal(object, &watched); err != nil {
+					return fmt.Errorf("failed to decode watched job: %v", err)
+				}
+				backoff.reset()
+				if eventType == "DELETED" {
+					return fmt.Errorf("job %s was deleted before it finished", name)
+				}
+				if JobFinished(&watched) != nil {
+					finished = &watched
+					return errWatchDone
+				}
+				return nil
+			})
+		}
+		if finished != nil {
+			if cond := JobFinished(finished); cond.Type == "Failed" {
+				return finished, fmt.Errorf("job %s failed: %s: %s", name, cond.Reason, cond.Message)
+			}
+			return finished, nil
+		}
+		if err != ErrWatchGone && err != ErrWatchClosed {
+			return nil, err
+		}
+		if err := backoff.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) jobURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(jobPath, namespace, name)
+}
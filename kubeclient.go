@@ -6,13 +6,12 @@
 package kubeclient
 
 import (
-	"crypto/tls"
 	"crypto/x509"
-	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
-	"os"
+	"strings"
 )
 
 const (
@@ -22,51 +21,81 @@ const (
 type Client struct {
 	Host   string
 	Client *http.Client
+
+	// Namespace is used by Create and Update methods for objects that do
+	// not set a namespace. If empty, "default" is used.
+	Namespace string
+
+	// PodPolicy, if set, is checked by CreatePod before a pod is sent.
+	PodPolicy *PodPolicy
+
+	// ConflictDiagnostics makes Create and Update methods that fail because
+	// the object already exists or was changed concurrently fetch the live
+	// object and list the fields that differ in the returned error.
+	ConflictDiagnostics bool
+
+	// Clock, if set, replaces the real clock in timeouts, backoffs, and
+	// polling waits, e.g. with a FakeClock in tests.
+	Clock Clock
+
+	// RetryPolicy, if set, retries requests that fail for transient
+	// reasons. It only applies to clients built by this package's
+	// constructors.
+	RetryPolicy *RetryPolicy
+
+	stats  *requestStats
+	groups *cancelGroups
 }
 
+// GetKubeClientFromEnv returns a client for the apiserver at
+// KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT, authenticated with
+// the cert.pem, key.pem, and ca.pem files in CERTS_PATH. See FromEnv to
+// override any of these.
 func GetKubeClientFromEnv() (*Client, error) {
-	certsPath := os.Getenv("CERTS_PATH")
-	apiServer := os.ExpandEnv("https://${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}")
+	return FromEnv()
+}
 
-	certFile := fmt.Sprintf("%s/%s", certsPath, "cert.pem")
-	keyFile := fmt.Sprintf("%s/%s", certsPath, "key.pem")
-	caFile := fmt.Sprintf("%s/%s", certsPath, "ca.pem")
-	certData, err := dataFromFile(certFile)
-	if err != nil {
-		return nil, errors.New("Couldn't load certificate")
-	}
-	keyData, err := dataFromFile(keyFile)
-	if err != nil {
-		return nil, errors.New("Couldn't load key")
-	}
-	caData, err := dataFromFile(caFile)
-	if err != nil {
-		return nil, errors.New("Couldn't load CA")
+// newClient returns a Client that sends requests to host through tr, with
+// request statistics, cancel groups, and retries under its RetryPolicy
+// enabled.
+func newClient(host string, tr http.RoundTripper) *Client {
+	c := &Client{
+		Host:   host,
+		stats:  newRequestStats(),
+		groups: newCancelGroups(),
 	}
-	tlsConfig := &tls.Config{
-		// Change default from SSLv3 to TLSv1.0 (because of POODLE vulnerability)
-		MinVersion: tls.VersionTLS10,
-		RootCAs:    rootCertPool(caData),
+	c.Client = &http.Client{
+		Transport: &cancelGroupTransport{
+			groups: c.groups,
+			rt: &retryTransport{
+				client: c,
+				rt:     &statsTransport{stats: c.stats, rt: tr},
+			},
+		},
 	}
+	return c
+}
 
-	cert, err := tls.X509KeyPair(certData, keyData)
-	if err != nil {
-		return nil, errors.New("Failed to build X509 KeyPair")
-	}
-	tlsConfig.Certificates = []tls.Certificate{cert}
+// apiServerURL joins host and port into an https URL, bracketing IPv6
+// literals so that addresses like fd00::1 produce a valid URL.
+func apiServerURL(host, port string) string {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return "https://" + net.JoinHostPort(host, port)
+}
 
-	tr := &http.Transport{
-		TLSClientConfig: tlsConfig,
+// resolveNamespace returns the namespace to send an object to: the object's
+// own namespace, else the one passed to the method, else c.Namespace, else
+// "default". It is an error for the object and method namespaces to differ.
+func (c *Client) resolveNamespace(methodNamespace, objectNamespace string) (string, error) {
+	if objectNamespace != "" && methodNamespace != "" && objectNamespace != methodNamespace {
+		return "", fmt.Errorf("object namespace %q does not match namespace %q", objectNamespace, methodNamespace)
 	}
-	httpClient := &http.Client{
-		Transport: tr,
-	}
-
-	client := Client{
-		Host:   apiServer,
-		Client: httpClient,
+	for _, ns := range []string{objectNamespace, methodNamespace, c.Namespace} {
+		if ns != "" {
+			return ns, nil
+		}
 	}
-	return &client, nil
+	return "default", nil
 }
 
 func dataFromFile(file string) ([]byte, error) {
@@ -0,0 +1,225 @@
+package kubeclient
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// kubeconfig is the subset of a kubeconfig file the client understands.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string            `yaml:"name"`
+		Cluster kubeconfigCluster `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string         `yaml:"name"`
+		User kubeconfigUser `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string            `yaml:"name"`
+		Context kubeconfigContext `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+type kubeconfigCluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+	TLSServerName            string `yaml:"tls-server-name"`
+}
+
+type kubeconfigUser struct {
+	ClientCertificate     string          `yaml:"client-certificate"`
+	ClientCertificateData string          `yaml:"client-certificate-data"`
+	ClientKey             string          `yaml:"client-key"`
+	ClientKeyData         string          `yaml:"client-key-data"`
+	Token                 string          `yaml:"token"`
+	TokenFile             string          `yaml:"tokenFile"`
+	Username              string          `yaml:"username"`
+	Password              string          `yaml:"password"`
+	Exec                  *kubeconfigExec `yaml:"exec"`
+	AuthProvider          interface{}     `yaml:"auth-provider"`
+}
+
+type kubeconfigExec struct {
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
+	APIVersion string   `yaml:"apiVersion"`
+	Env        []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+}
+
+type kubeconfigContext struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace"`
+}
+
+// NewClientFromKubeconfig returns a client for the named context of the
+// kubeconfig file at path, or for its current context if contextName is
+// empty. Client certificates, bearer tokens, token files, exec plugins, and
+// basic auth are supported; legacy auth-provider plugins are not. Client
+// certificate files are reloaded when they change. The client's Namespace is
+// the context's namespace.
+func NewClientFromKubeconfig(path, contextName string) (*Client, error) {
+	data, err := dataFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %v", err)
+	}
+	var config kubeconfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode kubeconfig %s: %v", path, err)
+	}
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("kubeconfig %s has no current context", path)
+	}
+
+	var kubeContext *kubeconfigContext
+	for i := range config.Contexts {
+		if config.Contexts[i].Name == contextName {
+			kubeContext = &config.Contexts[i].Context
+		}
+	}
+	if kubeContext == nil {
+		return nil, fmt.Errorf("kubeconfig %s has no context %q", path, contextName)
+	}
+	var cluster *kubeconfigCluster
+	for i := range config.Clusters {
+		if config.Clusters[i].Name == kubeContext.Cluster {
+			cluster = &config.Clusters[i].Cluster
+		}
+	}
+	if cluster == nil {
+		return nil, fmt.Errorf("kubeconfig %s has no cluster %q", path, kubeContext.Cluster)
+	}
+	var user *kubeconfigUser
+	for i := range config.Users {
+		if config.Users[i].Name == kubeContext.User {
+			user = &config.Users[i].User
+		}
+	}
+	if user == nil {
+		if kubeContext.User != "" {
+			return nil, fmt.Errorf("kubeconfig %s has no user %q", path, kubeContext.User)
+		}
+		user = &kubeconfigUser{}
+	}
+
+	// Relative paths in a kubeconfig are relative to the file itself.
+	dir := filepath.Dir(path)
+	resolve := func(file string) string {
+		if filepath.IsAbs(file) {
+			return file
+		}
+		return filepath.Join(dir, file)
+	}
+	load := func(inline, file, what string) ([]byte, error) {
+		if inline != "" {
+			decoded, err := base64.StdEncoding.DecodeString(inline)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s data: %v", what, err)
+			}
+			return decoded, nil
+		}
+		if file == "" {
+			return nil, nil
+		}
+		b, err := dataFromFile(resolve(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", what, err)
+		}
+		return b, nil
+	}
+
+	server, err := url.Parse(cluster.Server)
+	if err != nil || server.Host == "" {
+		return nil, fmt.Errorf("invalid server %q for cluster %q", cluster.Server, kubeContext.Cluster)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cluster.TLSServerName,
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+	caData, err := load(cluster.CertificateAuthorityData, cluster.CertificateAuthority, "certificate authority")
+	if err != nil {
+		return nil, err
+	}
+	if caData != nil {
+		tlsConfig.RootCAs = rootCertPool(caData)
+	}
+	if user.ClientCertificateData == "" && user.ClientKeyData == "" && user.ClientCertificate != "" && user.ClientKey != "" {
+		// Files are watched so rotated certificates are picked up.
+		files, err := newKeyPairFiles(resolve(user.ClientCertificate), resolve(user.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate for user %q: %v", kubeContext.User, err)
+		}
+		tlsConfig.GetClientCertificate = files.getClientCertificate
+	} else {
+		certData, err := load(user.ClientCertificateData, user.ClientCertificate, "client certificate")
+		if err != nil {
+			return nil, err
+		}
+		keyData, err := load(user.ClientKeyData, user.ClientKey, "client key")
+		if err != nil {
+			return nil, err
+		}
+		if certData != nil || keyData != nil {
+			cert, err := tls.X509KeyPair(certData, keyData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate for user %q: %v", kubeContext.User, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	var tr http.RoundTripper = &http.Transport{TLSClientConfig: tlsConfig}
+	switch {
+	case user.AuthProvider != nil:
+		return nil, fmt.Errorf("user %q uses an auth-provider plugin, which is not supported", kubeContext.User)
+	case user.Exec != nil:
+		config := ExecAuthConfig{
+			Command:    user.Exec.Command,
+			Args:       user.Exec.Args,
+			APIVersion: user.Exec.APIVersion,
+			Env:        make(map[string]string, len(user.Exec.Env)),
+		}
+		for _, env := range user.Exec.Env {
+			config.Env[env.Name] = env.Value
+		}
+		if config.Command == "" {
+			return nil, fmt.Errorf("user %q has an exec plugin without a command", kubeContext.User)
+		}
+		if strings.ContainsRune(config.Command, filepath.Separator) {
+			config.Command = resolve(config.Command)
+		}
+		exec := ExecAuth(config)
+		if tlsConfig.Certificates == nil && tlsConfig.GetClientCertificate == nil {
+			tlsConfig.GetClientCertificate = exec.(CertificateProvider).GetClientCertificate
+		}
+		tr = &authTransport{provider: exec, rt: tr}
+	case user.Token != "":
+		tr = &authTransport{provider: BearerTokenAuth(user.Token), rt: tr}
+	case user.TokenFile != "":
+		tr = &authTransport{provider: TokenFileAuth(resolve(user.TokenFile)), rt: tr}
+	case user.Username != "":
+		tr = &authTransport{provider: BasicAuth(user.Username, user.Password), rt: tr}
+	}
+
+	client := newClient(strings.TrimSuffix(cluster.Server, "/"), tr)
+	client.Namespace = kubeContext.Namespace
+	return client, nil
+}
@@ -0,0 +1,234 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const nodeStatsSummaryPath = apiPrefix + "/nodes/%s/proxy/stats/summary"
+
+// NodeSummary is the kubelet's stats summary for a node and the pods running
+// on it. Unlike metrics-server, it reports disk usage: each pod's ephemeral
+// storage and volumes, and the node's root and image filesystems.
+type NodeSummary struct {
+	Node NodeStats  `json:"node"`
+	Pods []PodStats `json:"pods"`
+}
+
+type NodeStats struct {
+	NodeName  string        `json:"nodeName"`
+	StartTime api.Time      `json:"startTime"`
+	Network   *NetworkStats `json:"network,omitempty"`
+	// Fs is the filesystem holding the kubelet's root directory, which
+	// ephemeral storage is drawn from.
+	Fs      *FsStats `json:"fs,omitempty"`
+	Runtime *struct {
+		ImageFs *FsStats `json:"imageFs,omitempty"`
+	} `json:"runtime,omitempty"`
+}
+
+type PodStats struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		UID       string `json:"uid"`
+	} `json:"podRef"`
+	StartTime  api.Time         `json:"startTime"`
+	Containers []ContainerStats `json:"containers,omitempty"`
+	Network    *NetworkStats    `json:"network,omitempty"`
+	Volumes    []VolumeStats    `json:"volume,omitempty"`
+	// EphemeralStorage is the pod's usage of the node's root filesystem:
+	// its containers' writable layers and logs, and its emptyDir volumes.
+	// It is what the kubelet compares to ephemeral-storage limits and
+	// evicts on.
+	EphemeralStorage *FsStats `json:"ephemeral-storage,omitempty"`
+}
+
+type ContainerStats struct {
+	Name      string   `json:"name"`
+	StartTime api.Time `json:"startTime"`
+	Rootfs    *FsStats `json:"rootfs,omitempty"`
+	Logs      *FsStats `json:"logs,omitempty"`
+}
+
+type VolumeStats struct {
+	FsStats `json:",inline"`
+	Name    string `json:"name"`
+}
+
+// FsStats reports a filesystem's usage. Fields the kubelet could not measure
+// are nil.
+type FsStats struct {
+	Time           api.Time `json:"time"`
+	AvailableBytes *uint64  `json:"availableBytes,omitempty"`
+	CapacityBytes  *uint64  `json:"capacityBytes,omitempty"`
+	UsedBytes      *uint64  `json:"usedBytes,omitempty"`
+	InodesFree     *uint64  `json:"inodesFree,omitempty"`
+	Inodes         *uint64  `json:"inodes,omitempty"`
+	InodesUsed     *uint64  `json:"inodesUsed,omitempty"`
+}
+
+// NetworkStats reports the cumulative traffic of the default interface,
+// with every interface broken out in Interfaces.
+type NetworkStats struct {
+	InterfaceStats `json:",inline"`
+	Time           api.Time         `json:"time"`
+	Interfaces     []InterfaceStats `json:"interfaces,omitempty"`
+}
+
+type InterfaceStats struct {
+	Name     string  `json:"name"`
+	RxBytes  *uint64 `json:"rxBytes,omitempty"`
+	RxErrors *uint64 `json:"rxErrors,omitempty"`
+	TxBytes  *uint64 `json:"txBytes,omitempty"`
+	TxErrors *uint64 `json:"txErrors,omitempty"`
+}
+
+// NodeStatsSummary fetches the stats summary of the named node from its
+// kubelet, through the apiserver's node proxy. This requires the get
+// permission on the nodes/proxy resource.
+func (c *Client) NodeStatsSummary(ctx context.Context, nodeName string) (*NodeSummary, error) {
+	apiResult, err := GetKubeResource(ctx, c.Host+fmt.Sprintf(nodeStatsSummaryPath, nodeName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var summary NodeSummary
+	if err := json.Unmarshal(apiResult, &summary); err != nil {
+		return nil, fmt.Errorf("failed to decode node stats summary json: %v", err)
+	}
+	return &summary, nil
+}
+
+// PodStats returns the stats of the pod in the summary, or nil if the
+// kubelet did not report it.
+func (s *NodeSummary) PodStats(namespace, name string) *PodStats {
+	for i := range s.Pods {
+		if ref := s.Pods[i].PodRef; ref.Namespace == namespace && ref.Name == name {
+			return &s.Pods[i]
+		}
+	}
+	return nil
+}
+
+// PodStorageUsage is a pod's usage of its node's disk, in bytes, as its
+// kubelet last measured it. Usage the kubelet could not measure is zero.
+type PodStorageUsage struct {
+	Namespace string
+	Pod       string
+	Node      string
+	// EphemeralStorage is the pod's total use of the node's root
+	// filesystem, which the kubelet evicts the pod for if it exceeds
+	// EphemeralStorageLimit.
+	EphemeralStorage uint64
+	// EphemeralStorageLimit is the sum of its containers'
+	// ephemeral-storage limits, or zero if none sets one.
+	EphemeralStorageLimit uint64
+	Containers            []ContainerStorageUsage
+	Volumes               []VolumeStorageUsage
+	// NodeAvailable is what is left of the node's root filesystem. When
+	// it runs low the kubelet evicts the pods using the most.
+	NodeAvailable uint64
+}
+
+// ContainerStorageUsage is a container's writable layer and log usage.
+type ContainerStorageUsage struct {
+	Name          string
+	WritableLayer uint64
+	Logs          uint64
+	// Limit is the container's ephemeral-storage limit, or zero if it has
+	// none.
+	Limit uint64
+}
+
+// VolumeStorageUsage is a volume's usage. Capacity is that of the
+// filesystem backing it, which for emptyDir volumes is the node's.
+type VolumeStorageUsage struct {
+	Name     string
+	Used     uint64
+	Capacity uint64
+}
+
+// limitedPod is the part of a pod PodStorageUsage needs; the api package
+// does not decode resource quantities.
+type limitedPod struct {
+	Spec struct {
+		NodeName   string `json:"nodeName"`
+		Containers []struct {
+			Name      string `json:"name"`
+			Resources struct {
+				Limits map[string]string `json:"limits"`
+			} `json:"resources"`
+		} `json:"containers"`
+	} `json:"spec"`
+}
+
+// PodStorageUsage reports the pod's ephemeral storage and volume usage from
+// the stats summary of its node.
+func (c *Client) PodStorageUsage(ctx context.Context, namespace, podName string) (*PodStorageUsage, error) {
+	apiResult, err := GetKubeResource(ctx, c.podURL(namespace, podName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var pod limitedPod
+	if err := json.Unmarshal(apiResult, &pod); err != nil {
+		return nil, fmt.Errorf("failed to decode pod json: %v", err)
+	}
+	if pod.Spec.NodeName == "" {
+		return nil, fmt.Errorf("pod %s/%s is not scheduled to a node", namespace, podName)
+	}
+
+	summary, err := c.NodeStatsSummary(ctx, pod.Spec.NodeName)
+	if err != nil {
+		return nil, err
+	}
+	stats := summary.PodStats(namespace, podName)
+	if stats == nil {
+		return nil, fmt.Errorf("node %s does not report stats for pod %s/%s", pod.Spec.NodeName, namespace, podName)
+	}
+
+	usage := &PodStorageUsage{
+		Namespace:        namespace,
+		Pod:              podName,
+		Node:             pod.Spec.NodeName,
+		EphemeralStorage: usedBytes(stats.EphemeralStorage),
+	}
+	if fs := summary.Node.Fs; fs != nil && fs.AvailableBytes != nil {
+		usage.NodeAvailable = *fs.AvailableBytes
+	}
+	limits := map[string]uint64{}
+	for _, container := range pod.Spec.Containers {
+		if q, ok := parseQuantity(container.Resources.Limits["ephemeral-storage"]); ok {
+			limit, _ := new(big.Float).SetRat(q).Uint64()
+			limits[container.Name] = limit
+			usage.EphemeralStorageLimit += limit
+		}
+	}
+	for _, container := range stats.Containers {
+		usage.Containers = append(usage.Containers, ContainerStorageUsage{
+			Name:          container.Name,
+			WritableLayer: usedBytes(container.Rootfs),
+			Logs:          usedBytes(container.Logs),
+			Limit:         limits[container.Name],
+		})
+	}
+	for i := range stats.Volumes {
+		volume := &stats.Volumes[i]
+		v := VolumeStorageUsage{Name: volume.Name, Used: usedBytes(&volume.FsStats)}
+		if volume.CapacityBytes != nil {
+			v.Capacity = *volume.CapacityBytes
+		}
+		usage.Volumes = append(usage.Volumes, v)
+	}
+	return usage, nil
+}
+
+func usedBytes(fs *FsStats) uint64 {
+	if fs == nil || fs.UsedBytes == nil {
+		return 0
+	}
+	return *fs.UsedBytes
+}
@@ -0,0 +1,134 @@
+// Package labels contains the well-known label and annotation keys that
+// Kubernetes and its tooling set, with helpers for reading them, so that
+// callers do not have to spell the keys out.
+package labels
+
+import (
+	"sort"
+	"strings"
+)
+
+// Recommended application labels, see
+// https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/.
+const (
+	AppName      = "app.kubernetes.io/name"
+	AppInstance  = "app.kubernetes.io/instance"
+	AppVersion   = "app.kubernetes.io/version"
+	AppComponent = "app.kubernetes.io/component"
+	AppPartOf    = "app.kubernetes.io/part-of"
+	AppManagedBy = "app.kubernetes.io/managed-by"
+)
+
+// Node labels set by the kubelet and cloud providers.
+const (
+	Hostname       = "kubernetes.io/hostname"
+	OS             = "kubernetes.io/os"
+	Arch           = "kubernetes.io/arch"
+	InstanceType   = "node.kubernetes.io/instance-type"
+	TopologyZone   = "topology.kubernetes.io/zone"
+	TopologyRegion = "topology.kubernetes.io/region"
+	ExcludeFromLB  = "node.kubernetes.io/exclude-from-external-load-balancers"
+	NodeRolePrefix = "node-role.kubernetes.io/"
+	ControlPlane   = NodeRolePrefix + "control-plane"
+	LegacyMaster   = NodeRolePrefix + "master"
+	legacyZone     = "failure-domain.beta.kubernetes.io/zone"
+	legacyRegion   = "failure-domain.beta.kubernetes.io/region"
+	legacyInstance = "beta.kubernetes.io/instance-type"
+)
+
+// Labels set by Kubernetes controllers on the objects they manage.
+const (
+	// ServiceName ties an EndpointSlice to its service.
+	ServiceName = "kubernetes.io/service-name"
+	// ManagedBy names the controller managing an EndpointSlice.
+	ManagedBy = "endpointslice.kubernetes.io/managed-by"
+	// PodTemplateHash distinguishes the ReplicaSets of a Deployment.
+	PodTemplateHash = "pod-template-hash"
+	// ControllerRevisionHash distinguishes the revisions of a StatefulSet
+	// or DaemonSet.
+	ControllerRevisionHash = "controller-revision-hash"
+	// StatefulSetPodName is the name of a StatefulSet pod, for selecting
+	// it alone.
+	StatefulSetPodName = "statefulset.kubernetes.io/pod-name"
+	// JobName is the name of the Job that created a pod.
+	JobName = "batch.kubernetes.io/job-name"
+	// MetadataName is set on every namespace to its own name.
+	MetadataName = "kubernetes.io/metadata.name"
+)
+
+// Well-known annotations.
+const (
+	// LastAppliedConfiguration holds the configuration kubectl apply last
+	// applied.
+	LastAppliedConfiguration = "kubectl.kubernetes.io/last-applied-configuration"
+	// RestartedAt is set on a pod template by kubectl rollout restart.
+	RestartedAt = "kubectl.kubernetes.io/restartedAt"
+	// DefaultContainer names the container kubectl logs and exec use.
+	DefaultContainer = "kubectl.kubernetes.io/default-container"
+	// TopologyMode enables topology-aware routing for a service.
+	TopologyMode = "service.kubernetes.io/topology-mode"
+	// DeploymentRevision is the revision of a Deployment or ReplicaSet.
+	DeploymentRevision = "deployment.kubernetes.io/revision"
+	// SafeToEvict tells the cluster autoscaler whether it may evict a pod.
+	SafeToEvict = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+)
+
+// Zone returns the topology zone of a node from its labels, falling back
+// to the deprecated beta label older clusters set.
+func Zone(labels map[string]string) string {
+	return first(labels, TopologyZone, legacyZone)
+}
+
+// Region returns the topology region of a node from its labels, falling
+// back to the deprecated beta label older clusters set.
+func Region(labels map[string]string) string {
+	return first(labels, TopologyRegion, legacyRegion)
+}
+
+// NodeInstanceType returns the instance type of a node from its labels,
+// falling back to the deprecated beta label older clusters set.
+func NodeInstanceType(labels map[string]string) string {
+	return first(labels, InstanceType, legacyInstance)
+}
+
+// NodeRoles returns the roles of a node, taken from its
+// node-role.kubernetes.io/<role> labels, sorted.
+func NodeRoles(labels map[string]string) []string {
+	var roles []string
+	for k := range labels {
+		if role := strings.TrimPrefix(k, NodeRolePrefix); role != k && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// IsControlPlane reports whether a node's labels mark it as part of the
+// control plane, under either the current or the legacy master role.
+func IsControlPlane(labels map[string]string) bool {
+	_, cp := labels[ControlPlane]
+	_, master := labels[LegacyMaster]
+	return cp || master
+}
+
+// App returns the recommended application labels of an object, as a map
+// holding only those keys.
+func App(labels map[string]string) map[string]string {
+	app := map[string]string{}
+	for _, k := range []string{AppName, AppInstance, AppVersion, AppComponent, AppPartOf, AppManagedBy} {
+		if v, ok := labels[k]; ok {
+			app[k] = v
+		}
+	}
+	return app
+}
+
+func first(labels map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := labels[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
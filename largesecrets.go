@@ -0,0 +1,259 @@
+package kubeclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// MaxSecretSize is the most data, keys and values together, the apiserver
+// accepts in a single secret.
+const MaxSecretSize = 1 << 20
+
+const (
+	// SecretChunksAnnotation is set on a secret stored by
+	// CreateLargeSecret to the number of chunk secrets holding its data.
+	SecretChunksAnnotation = "kubeclient/chunks"
+	// SecretEncodingAnnotation is "gzip" on a large secret whose data was
+	// compressed before it was split.
+	SecretEncodingAnnotation = "kubeclient/chunk-encoding"
+	// SecretChunkIndexAnnotation is set on each chunk secret to its
+	// position, counting from 0.
+	SecretChunkIndexAnnotation = "kubeclient/chunk-index"
+	// SecretChunkOfLabel is set on each chunk secret to the name of the
+	// secret it is part of.
+	SecretChunkOfLabel = "kubeclient/chunk-of"
+
+	// secretChunkSize leaves room in each chunk secret for its key.
+	secretChunkSize = MaxSecretSize - 1024
+	secretChunkKey  = "chunk"
+)
+
+// SecretDataSize returns the size of secret's data as the apiserver counts
+// it against MaxSecretSize.
+func SecretDataSize(secret *api.Secret) int {
+	size := 0
+	for k, v := range secret.Data {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// validateSecretSize fails for a secret the apiserver would reject as too
+// large, before it is sent.
+func validateSecretSize(secret *api.Secret) error {
+	if size := SecretDataSize(secret); size > MaxSecretSize {
+		return fmt.Errorf("secret %s has %d bytes of data, more than the %d allowed; see CreateLargeSecret", secret.Name, size, MaxSecretSize)
+	}
+	return nil
+}
+
+// LargeSecretOptions controls how CreateLargeSecret stores a secret.
+type LargeSecretOptions struct {
+	// Gzip compresses the data before it is split into chunks, which
+	// often saves chunks but makes the data unreadable to anything but
+	// GetLargeSecret.
+	Gzip bool
+}
+
+// CreateLargeSecret creates a secret whose data may exceed MaxSecretSize. A
+// secret that fits, and is not to be compressed, is created as it is.
+// Otherwise its data is encoded, compressed if opts.Gzip is set, and split
+// across chunk secrets named <name>-chunk-<index>, and the secret itself is
+// created without data, annotated with the number of chunks, once they all
+// exist. Read it back with GetLargeSecret and delete it with
+// DeleteLargeSecret.
+func (c *Client) CreateLargeSecret(ctx context.Context, secret *api.Secret, opts LargeSecretOptions) (*api.Secret, error) {
+	if SecretDataSize(secret) <= MaxSecretSize && !opts.Gzip {
+		return c.CreateSecret(ctx, secret)
+	}
+	namespace, err := c.resolveNamespace("", secret.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encodeSecretData(secret.Data, opts.Gzip)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []string
+	for i := 0; len(data) > 0; i++ {
+		n := secretChunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		chunk := &api.Secret{
+			ObjectMeta: api.ObjectMeta{
+				Name:        fmt.Sprintf("%s-chunk-%d", secret.Name, i),
+				Namespace:   namespace,
+				Labels:      map[string]string{SecretChunkOfLabel: secret.Name},
+				Annotations: map[string]string{SecretChunkIndexAnnotation: strconv.Itoa(i)},
+			},
+			Data: map[string][]byte{secretChunkKey: data[:n]},
+		}
+		if _, err := c.CreateSecret(ctx, chunk); err != nil {
+			c.deleteSecretChunks(namespace, chunks)
+			return nil, err
+		}
+		chunks = append(chunks, chunk.Name)
+		data = data[n:]
+	}
+
+	head := *secret
+	head.Namespace = namespace
+	head.Data = nil
+	head.Annotations = make(map[string]string, len(secret.Annotations)+2)
+	for k, v := range secret.Annotations {
+		head.Annotations[k] = v
+	}
+	head.Annotations[SecretChunksAnnotation] = strconv.Itoa(len(chunks))
+	if opts.Gzip {
+		head.Annotations[SecretEncodingAnnotation] = "gzip"
+	}
+	created, err := c.CreateSecret(ctx, &head)
+	if err != nil {
+		c.deleteSecretChunks(namespace, chunks)
+		return nil, err
+	}
+	return created, nil
+}
+
+// deleteSecretChunks cleans up after a CreateLargeSecret that failed part
+// way, even if its context is done.
+func (c *Client) deleteSecretChunks(namespace string, chunks []string) {
+	for _, name := range chunks {
+		c.DeleteSecret(context.Background(), namespace, name)
+	}
+}
+
+// GetLargeSecret gets a secret stored by CreateLargeSecret, reassembling its
+// data from its chunks. Secrets that were not split are returned as they
+// are.
+func (c *Client) GetLargeSecret(ctx context.Context, namespace, name string) (*api.Secret, error) {
+	secret, err := c.GetSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	count, ok := secret.Annotations[SecretChunksAnnotation]
+	if !ok {
+		return secret, nil
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("secret %s has an invalid %s annotation %q", name, SecretChunksAnnotation, count)
+	}
+
+	var data []byte
+	for i := 0; i < n; i++ {
+		chunk, err := c.GetSecret(ctx, namespace, fmt.Sprintf("%s-chunk-%d", name, i))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chunk %d of secret %s: %w", i, name, err)
+		}
+		if chunk.Labels[SecretChunkOfLabel] != name || chunk.Annotations[SecretChunkIndexAnnotation] != strconv.Itoa(i) {
+			return nil, fmt.Errorf("secret %s is not chunk %d of secret %s", chunk.Name, i, name)
+		}
+		data = append(data, chunk.Data[secretChunkKey]...)
+	}
+	secret.Data, err = decodeSecretData(data, secret.Annotations[SecretEncodingAnnotation] == "gzip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to reassemble secret %s: %v", name, err)
+	}
+	delete(secret.Annotations, SecretChunksAnnotation)
+	delete(secret.Annotations, SecretEncodingAnnotation)
+	return secret, nil
+}
+
+// DeleteLargeSecret deletes a secret stored by CreateLargeSecret along with
+// its chunks.
+func (c *Client) DeleteLargeSecret(ctx context.Context, namespace, name string) error {
+	if err := c.DeleteSecret(ctx, namespace, name); err != nil && !IsNotFound(err) {
+		return err
+	}
+	sel := NewSelector().Equals(SecretChunkOfLabel, name)
+	_, err := DeleteKubeResourceCollection(ctx, WithSelector(&SecretResource{c.Host, namespace, ""}, sel), DeleteOptions{}, c.Client)
+	return err
+}
+
+// encodeSecretData serializes data as a sequence of length-prefixed keys
+// and values, in sorted key order, gzipped if compress is set.
+func encodeSecretData(data map[string][]byte, compress bool) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		w = gz
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var n [binary.MaxVarintLen64]byte
+	for _, k := range keys {
+		for _, field := range [][]byte{[]byte(k), data[k]} {
+			if _, err := w.Write(n[:binary.PutUvarint(n[:], uint64(len(field)))]); err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress secret data: %v", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSecretData reverses encodeSecretData.
+func decodeSecretData(encoded []byte, compressed bool) (map[string][]byte, error) {
+	if compressed {
+		gz, err := gzip.NewReader(bytes.NewReader(encoded))
+		if err != nil {
+			return nil, err
+		}
+		if encoded, err = ioutil.ReadAll(gz); err != nil {
+			return nil, err
+		}
+	}
+	r := bufio.NewReader(bytes.NewReader(encoded))
+	readField := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(encoded)) {
+			return nil, fmt.Errorf("field length %d exceeds the data", n)
+		}
+		field := make([]byte, n)
+		_, err = io.ReadFull(r, field)
+		return field, err
+	}
+	data := make(map[string][]byte)
+	for {
+		key, err := readField()
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		value, err := readField()
+		if err != nil {
+			return nil, fmt.Errorf("truncated value for key %q", key)
+		}
+		data[string(key)] = value
+	}
+}
@@ -0,0 +1,86 @@
+package kubeclient
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSecretDataRoundTrip(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789abcdef"), 1<<12)
+	tests := []struct {
+		name string
+		data map[string][]byte
+	}{
+		{"empty", map[string][]byte{}},
+		{"empty value", map[string][]byte{"a": {}}},
+		{"several keys", map[string][]byte{"b": []byte("2"), "a": []byte("1"), "c": []byte("3")}},
+		{"binary", map[string][]byte{"bin": {0, 0xff, 0x80, '\n', 0}}},
+		{"long fields", map[string][]byte{string(large[:300]): large}},
+	}
+	for _, tt := range tests {
+		for _, compress := range []bool{false, true} {
+			encoded, err := encodeSecretData(tt.data, compress)
+			if err != nil {
+				t.Errorf("%s: compress=%v: encode: %v", tt.name, compress, err)
+				continue
+			}
+			// Split and reassemble the data the way chunk secrets do,
+			// with boundaries inside the length prefixes and fields.
+			var reassembled []byte
+			for rest := encoded; len(rest) > 0; {
+				n := 7
+				if n > len(rest) {
+					n = len(rest)
+				}
+				reassembled = append(reassembled, rest[:n]...)
+				rest = rest[n:]
+			}
+			got, err := decodeSecretData(reassembled, compress)
+			if err != nil {
+				t.Errorf("%s: compress=%v: decode: %v", tt.name, compress, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, tt.data) {
+				t.Errorf("%s: compress=%v: got %q, want %q", tt.name, compress, got, tt.data)
+			}
+		}
+	}
+}
+
+func TestEncodeSecretDataSorted(t *testing.T) {
+	data := map[string][]byte{"b": []byte("2"), "a": []byte("1")}
+	for i := 0; i < 10; i++ {
+		encoded, err := encodeSecretData(data, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []byte("\x01a\x011\x01b\x012"); !bytes.Equal(encoded, want) {
+			t.Fatalf("got %q, want %q", encoded, want)
+		}
+	}
+}
+
+func TestDecodeSecretDataErrors(t *testing.T) {
+	encoded, err := encodeSecretData(map[string][]byte{"key": []byte("value")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		encoded    []byte
+		compressed bool
+	}{
+		{"truncated key", encoded[:2], false},
+		{"missing value", encoded[:4], false},
+		{"truncated value", encoded[:len(encoded)-1], false},
+		{"length past the end", []byte{0x7f, 'a'}, false},
+		{"unterminated length", []byte{0x80}, false},
+		{"not gzip", encoded, true},
+	}
+	for _, tt := range tests {
+		if got, err := decodeSecretData(tt.encoded, tt.compressed); err == nil {
+			t.Errorf("%s: got %q, want an error", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package kubeclient
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// PodLog retrieves the log of the named container in the pod. An empty
+// container selects the pod's only container; for pods with several
+// containers the server rejects the request with a 400, so name the
+// container or use PodLogAllContainers.
+func (c *Client) PodLog(ctx context.Context, namespace, podName, container string) (string, error) {
+	return c.podLog(ctx, namespace, podName, PodLogOptions{Container: container}.values())
+}
+
+// PodLogAllContainers retrieves the log of each container in the pod, by
+// container name, for pods with sidecars. Logs that cannot be retrieved,
+// such as those of containers that have not started, are left out and
+// reported together in an AggregateError.
+func (c *Client) PodLogAllContainers(ctx context.Context, namespace, podName string) (map[string]string, error) {
+	pod, err := c.GetPod(ctx, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	logs := make(map[string]string, len(pod.Spec.Containers))
+	var failed AggregateError
+	for _, container := range pod.Spec.Containers {
+		log, err := c.PodLog(ctx, namespace, podName, container.Name)
+		if err != nil {
+			ref := api.ObjectReference{Kind: "Pod", Namespace: namespace, Name: podName, FieldPath: "spec.containers{" + container.Name + "}"}
+			failed.add("get log of", ref, err)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		logs[container.Name] = log
+	}
+	return logs, failed.err()
+}
+
+// PodLogSince retrieves the log of the named container in the pod, starting
+// with the first line written at or after since. An empty container is
+// treated as for PodLog.
+func (c *Client) PodLogSince(ctx context.Context, namespace, podName, container string, since time.Time) (string, error) {
+	values := PodLogOptions{Container: container}.values()
+	values.Set("sinceTime", since.UTC().Format(time.RFC3339))
+	return c.podLog(ctx, namespace, podName, values)
+}
+
+// PodLogSinceRestart retrieves the log of the named container in the pod,
+// written since that container last (re)started. If the container has not
+// started yet, its whole log is returned. An empty container is treated as
+// for PodLog.
+func (c *Client) PodLogSinceRestart(ctx context.Context, namespace, podName, container string) (string, error) {
+	pod, err := c.GetPod(ctx, namespace, podName)
+	if err != nil {
+		return "", err
+	}
+	started := containerStartTime(pod, container)
+	if started.IsZero() {
+		return c.PodLog(ctx, namespace, podName, container)
+	}
+	return c.PodLogSince(ctx, namespace, podName, container, started)
+}
+
+// LogRecord is a single line of a container log. Timestamp is the time the
+// kubelet recorded for the line, and is zero if the log was requested
+// without timestamps or the line carried none.
+type LogRecord struct {
+	Timestamp time.Time
+	Line      string
+}
+
+// PodLogRecords retrieves the log of the named container in the pod with
+// timestamps, split into records. An empty container is treated as for
+// PodLog.
+func (c *Client) PodLogRecords(ctx context.Context, namespace, podName, container string) ([]LogRecord, error) {
+	values := PodLogOptions{Container: container, Timestamps: true}.values()
+	log, err := c.podLog(ctx, namespace, podName, values)
+	if err != nil {
+		return nil, err
+	}
+	var records []LogRecord
+	scanner := NewLogScanner(strings.NewReader(log))
+	for scanner.Scan() {
+		records = append(records, scanner.Record())
+	}
+	return records, scanner.Err()
+}
+
+// maxLogLineSize is the longest log line a LogScanner accepts.
+const maxLogLineSize = 1024 * 1024
+
+// LogScanner splits a container log requested with timestamps=true into
+// LogRecords. It is used like a bufio.Scanner.
+type LogScanner struct {
+	scanner *bufio.Scanner
+	record  LogRecord
+}
+
+// NewLogScanner returns a LogScanner reading from r.
+func NewLogScanner(r io.Reader) *LogScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	return &LogScanner{scanner: scanner}
+}
+
+// Scan advances to the next record, returning false at the end of the log
+// or on error.
+func (s *LogScanner) Scan() bool {
+	if !s.scanner.Scan() {
+		return false
+	}
+	s.record = ParseLogLine(s.scanner.Text())
+	return true
+}
+
+// Record returns the record read by the last call to Scan.
+func (s *LogScanner) Record() LogRecord {
+	return s.record
+}
+
+// Err returns the first error encountered reading the log.
+func (s *LogScanner) Err() error {
+	return s.scanner.Err()
+}
+
+// ParseLogLine splits the RFC3339 timestamp the kubelet prefixes each line
+// with when timestamps=true from the rest of the line. Lines without a
+// valid timestamp are returned whole with a zero Timestamp.
+func ParseLogLine(line string) LogRecord {
+	i := strings.IndexByte(line, ' ')
+	if i < 0 {
+		return LogRecord{Line: line}
+	}
+	t, err := time.Parse(time.RFC3339Nano, line[:i])
+	if err != nil {
+		return LogRecord{Line: line}
+	}
+	return LogRecord{Timestamp: t, Line: line[i+1:]}
+}
+
+// containerStartTime returns when the named container in the pod, or its
+// only container if container is empty, last started, or the zero time if
+// it is unknown.
+func containerStartTime(pod *api.Pod, container string) time.Time {
+	if container == "" {
+		if len(pod.Spec.Containers) != 1 {
+			return time.Time{}
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != container {
+			continue
+		}
+		switch {
+		case status.State.Running != nil:
+			return status.State.Running.StartedAt.UTC()
+		case status.State.Terminated != nil:
+			return status.State.Terminated.StartedAt.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// PodLogOptions selects which part of a container log PodLogStream returns.
+// The zero value selects the whole log of the pod's only container.
+type PodLogOptions struct {
+	// Container is the container to read; empty means the pod's only
+	// container, as for PodLog.
+	Container string
+	// Follow keeps the stream open, sending lines as they are written, until
+	// the container exits or ctx is done.
+	Follow bool
+	// TailLines, if set, starts the log this many lines before its end.
+	TailLines *int64
+	// SinceSeconds, if set, starts the log at lines written this many
+	// seconds ago.
+	SinceSeconds *int64
+	// Timestamps prefixes each line with its RFC3339 timestamp; use a
+	// LogScanner to split them off.
+	Timestamps bool
+	// Previous reads the log of the previous, terminated instance of the
+	// container.
+	Previous bool
+}
+
+func (opts PodLogOptions) values() url.Values {
+	values := url.Values{}
+	if opts.Container != "" {
+		values.Set("container", opts.Container)
+	}
+	if opts.Follow {
+		values.Set("follow", "true")
+	}
+	if opts.TailLines != nil {
+		values.Set("tailLines", strconv.FormatInt(*opts.TailLines, 10))
+	}
+	if opts.SinceSeconds != nil {
+		values.Set("sinceSeconds", strconv.FormatInt(*opts.SinceSeconds, 10))
+	}
+	if opts.Timestamps {
+		values.Set("timestamps", "true")
+	}
+	if opts.Previous {
+		values.Set("previous", "true")
+	}
+	return values
+}
+
+// PodLogStream returns the container log selected by opts as a stream,
+// without buffering it, so long-running pods can be tailed with Follow.
+// The caller must close the returned reader; reads fail once ctx is done.
+func (c *Client) PodLogStream(ctx context.Context, namespace, podName string, opts PodLogOptions) (io.ReadCloser, error) {
+	return c.podLogStream(ctx, namespace, podName, opts.values())
+}
+
+func (c *Client) podLog(ctx context.Context, namespace, podName string, values url.Values) (string, error) {
+	stream, err := c.podLogStream(ctx, namespace, podName, values)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	body, err := ioutil.ReadAll(stream)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: GET %q: %v", c.podURL(namespace, podName)+"/log", err)
+	}
+	return string(body), nil
+}
+
+func (c *Client) podLogStream(ctx context.Context, namespace, podName string, values url.Values) (io.ReadCloser, error) {
+	url := c.podURL(namespace, podName) + "/log"
+	if len(values) > 0 {
+		url += "?" + values.Encode()
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: GET %q : %v", url, err)
+	}
+	res, err := doRequest(ctx, c.Client, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: GET %q: %v", url, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return nil, newStatusError("GET", url, res.StatusCode, body)
+	}
+	return res.Body, nil
+}
@@ -0,0 +1,119 @@
+package kubeclient
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const defaultMigratePollInterval = 2 * time.Second
+
+// MigrateOptions configures MigrateRCToDeployment.
+type MigrateOptions struct {
+	// DeploymentName names the new Deployment. It defaults to the name of
+	// the replication controller.
+	DeploymentName string
+	// Step is the number of pods moved from the replication controller to
+	// the Deployment at a time. It defaults to 1.
+	Step int
+	// PollInterval is how often the Deployment is checked for
+	// availability. It defaults to 2 seconds.
+	PollInterval time.Duration
+}
+
+// MigrateRCToDeployment replaces a replication controller with an
+// equivalent Deployment without dropping capacity. The Deployment is created
+// with no replicas and the same selector and pod template, then scaled up
+// opts.Step pods at a time; after each step becomes available, the
+// replication controller is scaled down by the same amount. Once the
+// Deployment serves every replica, the replication controller is deleted.
+// Pods are recreated rather than adopted, because the Deployment's
+// ReplicaSets only match pods carrying their pod-template-hash label.
+// If ctx is done or a step fails, the migration stops where it is and both
+// objects are left in place, so it can be finished or rolled back by hand.
+func (c *Client) MigrateRCToDeployment(ctx context.Context, namespace, rcName string, opts MigrateOptions) (*Deployment, error) {
+	if opts.DeploymentName == "" {
+		opts.DeploymentName = rcName
+	}
+	if opts.Step <= 0 {
+		opts.Step = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultMigratePollInterval
+	}
+
+	rc, err := c.GetReplicationController(ctx, namespace, rcName)
+	if err != nil {
+		return nil, err
+	}
+	if rc.Spec.Template == nil {
+		return nil, fmt.Errorf("replication controller %s has no pod template", rcName)
+	}
+	selector := rc.Spec.Selector
+	if len(selector) == 0 {
+		selector = rc.Spec.Template.Labels
+	}
+
+	target := rc.Spec.Replicas
+	replicas := 0
+	deployment := &Deployment{
+		Spec: DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &LabelSelector{MatchLabels: selector},
+			Template: *rc.Spec.Template,
+		},
+	}
+	deployment.Name = opts.DeploymentName
+	deployment.Namespace = namespace
+	deployment.Labels = rc.Labels
+	if _, err := c.CreateDeployment(ctx, deployment); err != nil {
+		return nil, err
+	}
+
+	for moved := 0; moved < target; {
+		moved += opts.Step
+		if moved > target {
+			moved = target
+		}
+		if err := c.ScaleDeployment(ctx, namespace, opts.DeploymentName, moved); err != nil {
+			return nil, fmt.Errorf("failed to scale deployment %s to %d: %w", opts.DeploymentName, moved, err)
+		}
+		if _, err := c.awaitDeploymentAvailable(ctx, namespace, opts.DeploymentName, opts.PollInterval); err != nil {
+			return nil, err
+		}
+		if err := c.ScaleReplicationController(ctx, namespace, rcName, target-moved); err != nil {
+			return nil, fmt.Errorf("failed to scale replication controller %s to %d: %w", rcName, target-moved, err)
+		}
+	}
+
+	migrated, err := c.awaitDeploymentAvailable(ctx, namespace, opts.DeploymentName, opts.PollInterval)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.DeleteReplicationController(ctx, namespace, rcName); err != nil {
+		return nil, err
+	}
+	return migrated, nil
+}
+
+// awaitDeploymentAvailable polls the deployment until DeploymentAvailable
+// reports true or ctx is done.
+func (c *Client) awaitDeploymentAvailable(ctx context.Context, namespace, name string, interval time.Duration) (*Deployment, error) {
+	ticker := c.clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		deployment, err := c.GetDeployment(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if DeploymentAvailable(deployment) {
+			return deployment, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("deployment %s did not become available: %v", name, ctx.Err())
+		case <-ticker.C():
+		}
+	}
+}
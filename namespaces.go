@@ -0,0 +1,287 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	namespacesPath      = apiPrefix + "/namespaces"
+	namespacePath       = apiPrefix + "/namespaces/%s"
+	watchNamespacesPath = apiPrefix + "/watch/namespaces"
+	// namespaceDeletePollInterval is how often DeleteNamespace checks
+	// whether a namespace has finished terminating.
+	namespaceDeletePollInterval = time.Second
+)
+
+type NamespaceResource struct {
+	Host  string
+	Label string
+}
+
+func (ns *NamespaceResource) KubeResourcesURL() string {
+	return ns.Host + namespacesPath
+}
+
+// KubeResourceNamespace returns "" since namespaces are cluster-scoped.
+func (ns *NamespaceResource) KubeResourceNamespace() string {
+	return ""
+}
+
+func (ns *NamespaceResource) KubeResourceLabel() string {
+	return ns.Label
+}
+
+func (c *Client) CreateNamespace(ctx context.Context, namespace *api.Namespace) (*api.Namespace, error) {
+	var namespaceJSON bytes.Buffer
+	if err := json.NewEncoder(&namespaceJSON).Encode(namespace); err != nil {
+		return nil, fmt.Errorf("failed to encode namespace in json: %v", err)
+	}
+	apiResult, err := CreateKubeResource(ctx, &NamespaceResource{c.Host, ""}, namespaceJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.namespaceURL(namespace.Name), namespace)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var namespaceResult api.Namespace
+	if err := json.Unmarshal(apiResult, &namespaceResult); err != nil {
+		return nil, fmt.Errorf("failed to decode namespace resources: %v", err)
+	}
+	return &namespaceResult, nil
+}
+
+func (c *Client) GetNamespace(ctx context.Context, name string) (*api.Namespace, error) {
+	apiResult, err := GetKubeResource(ctx, c.namespaceURL(name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var namespace api.Namespace
+	if err := json.Unmarshal(apiResult, &namespace); err != nil {
+		return nil, fmt.Errorf("failed to decode namespace json: %v", err)
+	}
+	return &namespace, nil
+}
+
+func (c *Client) ListNamespaces(ctx context.Context, label string, opts ...ListOptions) ([]api.Namespace, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &NamespaceResource{c.Host, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var namespaceList api.NamespaceList
+	if err := json.Unmarshal(apiResult, &namespaceList); err != nil {
+		return nil, fmt.Errorf("failed to decode namespace resources: %v", err)
+	}
+	return namespaceList.Items, nil
+}
+
+// DeleteNamespace deletes the namespace and everything in it. If wait is
+// set, it blocks until the namespace has finished terminating and is gone,
+// or until ctx is done.
+func (c *Client) DeleteNamespace(ctx context.Context, name string, wait bool) error {
+	url := c.namespaceURL(name)
+	if err := DeleteKubeResource(ctx, url, c.Client); err != nil || !wait {
+		return err
+	}
+	ticker := c.clock().NewTicker(namespaceDeletePollInterval)
+	defer ticker.Stop()
+	for {
+		_, err := GetKubeResource(ctx, url, c.Client)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Resource Get failed: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("namespace %s did not finish terminating: %v", name, ctx.Err())
+		case <-ticker.C():
+		}
+	}
+}
+
+func (c *Client) namespaceURL(name string) string {
+	return c.Host + fmt.Sprintf(namespacePath, name)
+}
+
+// NamespaceHandlers are the lifecycle hooks invoked by WatchNamespaces. Any
+// of them may be nil.
+type NamespaceHandlers struct {
+	// Created is called when a namespace appears.
+	Created func(*api.Namespace)
+	// Terminating is called once when a namespace starts being deleted.
+	Terminating func(*api.Namespace)
+	// Deleted is called when a namespace has been removed.
+	Deleted func(*api.Namespace)
+	// Resync is called with the full list of namespaces after the watch
+	// expired (410 Gone) and had to re-list. Created and Deleted have
+	// already been called for the differences found by the re-list.
+	Resync func([]api.Namespace)
+}
+
+type watchNamespaceStatus struct {
+	// The type of watch update contained in the message
+	Type string `json:"type"`
+	// Namespace details, or an api.Status for ERROR updates
+	Object json.RawMessage `json:"object"`
+}
+
+// namespaceWatch holds the state WatchNamespaces keeps across reconnects.
+type namespaceWatch struct {
+	handlers    NamespaceHandlers
+	known       map[string]bool
+	terminating map[string]bool
+}
+
+func (w *namespaceWatch) created(ns *api.Namespace) {
+	w.known[ns.Name] = true
+	if w.handlers.Created != nil {
+		w.handlers.Created(ns)
+	}
+	w.modified(ns)
+}
+
+func (w *namespaceWatch) modified(ns *api.Namespace) {
+	if w.terminating[ns.Name] || (ns.Status.Phase != api.NamespaceTerminating && ns.DeletionTimestamp == nil) {
+		return
+	}
+	w.terminating[ns.Name] = true
+	if w.handlers.Terminating != nil {
+		w.handlers.Terminating(ns)
+	}
+}
+
+func (w *namespaceWatch) deleted(ns *api.Namespace) {
+	delete(w.known, ns.Name)
+	delete(w.terminating, ns.Name)
+	if w.handlers.Deleted != nil {
+		w.handlers.Deleted(ns)
+	}
+}
+
+// WatchNamespaces watches the namespaces matching label and invokes the
+// handlers as they are created, start terminating, and are deleted. If
+// resourceVersion is empty, Created is first called for every existing
+// namespace, which lets multi-tenant controllers set up per-namespace
+// resources for namespaces that predate them.
+// If the resourceVersion expires (410 Gone), the namespaces are re-listed,
+// the handlers are called for what changed in between, and the watch
+// resumes.
+// WatchNamespaces blocks until ctx is done or the watch fails, and returns
+// the reason it stopped.
+func (c *Client) WatchNamespaces(ctx context.Context, label, resourceVersion string, handlers NamespaceHandlers) error {
+	w := &namespaceWatch{
+		handlers:    handlers,
+		known:       make(map[string]bool),
+		terminating: make(map[string]bool),
+	}
+	backoff := watchBackoff{clock: c.clock()}
+	for {
+		err := c.watchNamespaces(ctx, label, resourceVersion, w, &backoff)
+		if err != ErrWatchGone {
+			return err
+		}
+		if err := backoff.wait(ctx); err != nil {
+			return err
+		}
+		apiResult, err := ListKubeResources(ctx, &NamespaceResource{c.Host, label}, c.Client)
+		if err != nil {
+			return fmt.Errorf("failed to relist namespaces after 410 Gone: %w", err)
+		}
+		var namespaceList api.NamespaceList
+		if err := json.Unmarshal(apiResult, &namespaceList); err != nil {
+			return fmt.Errorf("failed to decode namespace resources: %v", err)
+		}
+
+		listed := make(map[string]bool)
+		for i := range namespaceList.Items {
+			ns := &namespaceList.Items[i]
+			listed[ns.Name] = true
+			if w.known[ns.Name] {
+				w.modified(ns)
+			} else {
+				w.created(ns)
+			}
+		}
+		for name := range w.known {
+			if !listed[name] {
+				w.deleted(&api.Namespace{ObjectMeta: api.ObjectMeta{Name: name}})
+			}
+		}
+		if handlers.Resync != nil {
+			handlers.Resync(namespaceList.Items)
+		}
+		resourceVersion = namespaceList.ResourceVersion
+	}
+}
+
+// watchNamespaces runs a single watch connection, dispatching its events to
+// w, until the connection fails. It returns ErrWatchGone if resourceVersion
+// has expired.
+func (c *Client) watchNamespaces(ctx context.Context, label, resourceVersion string, w *namespaceWatch, backoff *watchBackoff) error {
+	values := url.Values{}
+	if label != "" {
+		values.Set("labelSelector", label)
+	}
+	if resourceVersion != "" {
+		values.Set("resourceVersion", resourceVersion)
+	}
+	getURL := c.Host + watchNamespacesPath + "?" + values.Encode()
+	req, err := http.NewRequest("GET", getURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: GET %q : %v", getURL, err)
+	}
+	res, err := doRequest(ctx, c.Client, req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: GET %q: %v", getURL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusGone {
+		return ErrWatchGone
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return newStatusError("GET", getURL, res.StatusCode, body)
+	}
+
+	// The decoder blocks on the response body, so close it when the
+	// context is done to unblock it.
+	stop := closeOnDone(ctx, res.Body)
+	defer stop()
+
+	decoder := json.NewDecoder(res.Body)
+	for {
+		var wns watchNamespaceStatus
+		err := decoder.Decode(&wns)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode watch namespace status: %v", err)
+		}
+		if wns.Type == "ERROR" {
+			return watchStatusError(wns.Object)
+		}
+		ns := new(api.Namespace)
+		if err := json.Unmarshal(wns.Object, ns); err != nil {
+			return fmt.Errorf("failed to decode watch namespace status: %v", err)
+		}
+		backoff.reset()
+
+		switch wns.Type {
+		case "ADDED":
+			w.created(ns)
+		case "MODIFIED":
+			w.modified(ns)
+		case "DELETED":
+			w.deleted(ns)
+		}
+	}
+}
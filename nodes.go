@@ -0,0 +1,140 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	nodesPath = apiPrefix + "/nodes"
+	nodePath  = apiPrefix + "/nodes/%s"
+)
+
+type NodeResource struct {
+	Host  string
+	Label string
+}
+
+func (node *NodeResource) KubeResourcesURL() string {
+	return node.Host + nodesPath
+}
+
+// KubeResourceNamespace returns "" since nodes are cluster-scoped.
+func (node *NodeResource) KubeResourceNamespace() string {
+	return ""
+}
+
+func (node *NodeResource) KubeResourceLabel() string {
+	return node.Label
+}
+
+func (c *Client) NodeList(ctx context.Context, label string, opts ...ListOptions) ([]api.Node, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &NodeResource{c.Host, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var nodeList api.NodeList
+	if err := json.Unmarshal(apiResult, &nodeList); err != nil {
+		return nil, fmt.Errorf("failed to decode node resources: %v", err)
+	}
+	return nodeList.Items, nil
+}
+
+func (c *Client) GetNode(ctx context.Context, name string) (*api.Node, error) {
+	apiResult, err := GetKubeResource(ctx, c.nodeURL(name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var node api.Node
+	if err := json.Unmarshal(apiResult, &node); err != nil {
+		return nil, fmt.Errorf("failed to decode node json: %v", err)
+	}
+	return &node, nil
+}
+
+// SetNodeUnschedulable cordons the node, so no new pods are scheduled onto
+// it, or uncordons it, and returns the patched node. Pods already running
+// on the node are left alone; see DrainNode to evict them.
+func (c *Client) SetNodeUnschedulable(ctx context.Context, name string, unschedulable bool) (*api.Node, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"unschedulable": unschedulable},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode node patch in json: %v", err)
+	}
+	body, err := PatchKubeResource(ctx, c.nodeURL(name), MergePatchType, patch, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Patch failed: %w", err)
+	}
+	var node api.Node
+	if err := json.Unmarshal(body, &node); err != nil {
+		return nil, fmt.Errorf("failed to decode node json: %v", err)
+	}
+	return &node, nil
+}
+
+type NodeStatusResult struct {
+	Node *api.Node
+	Type string
+	Err  error
+}
+
+// WatchNode watches the node for changes after nodeResourceVersion, sending
+// each on the returned channel. Like WatchPod, it survives the apiserver
+// closing the watch, and if the resourceVersion expires (410 Gone) it gets
+// the node again and sends it as a WatchResyncNeeded event before
+// resuming. The last result sent before the channel is closed carries the
+// error that ended the watch, ctx's error once it is done.
+func (c *Client) WatchNode(ctx context.Context, name, nodeResourceVersion string) (<-chan NodeStatusResult, error) {
+	if nodeResourceVersion == "" {
+		return nil, fmt.Errorf("resourceVersion for node %v must be provided", name)
+	}
+	statusChan := make(chan NodeStatusResult)
+
+	go func() {
+		defer close(statusChan)
+		resourceVersion := nodeResourceVersion
+		backoff := watchBackoff{clock: c.clock()}
+		for {
+			values := url.Values{}
+			values.Set("fieldSelector", "metadata.name="+name)
+			err := watchKubeResourcesQuery(ctx, &NodeResource{c.Host, ""}, values, resourceVersion, c.Client, func(eventType string, object json.RawMessage) error {
+				var node api.Node
+				if err := json.Unmarshal(object, &node); err != nil {
+					return fmt.Errorf("failed to decode watch node status: %v", err)
+				}
+				backoff.reset()
+				resourceVersion = node.ResourceVersion
+				statusChan <- NodeStatusResult{Node: &node, Type: eventType}
+				return nil
+			})
+			if err == ErrWatchClosed {
+				continue
+			}
+			if err != ErrWatchGone {
+				statusChan <- NodeStatusResult{Err: err}
+				return
+			}
+			if err := backoff.wait(ctx); err != nil {
+				statusChan <- NodeStatusResult{Err: err}
+				return
+			}
+			node, err := c.GetNode(ctx, name)
+			if err != nil {
+				statusChan <- NodeStatusResult{Err: fmt.Errorf("failed to relist node after 410 Gone: %w", err)}
+				return
+			}
+			statusChan <- NodeStatusResult{Node: node, Type: WatchResyncNeeded}
+			resourceVersion = node.ResourceVersion
+		}
+	}()
+	return statusChan, nil
+}
+
+func (c *Client) nodeURL(name string) string {
+	return c.Host + fmt.Sprintf(nodePath, name)
+}
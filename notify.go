@@ -0,0 +1,245 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// NotificationKind classifies a Notification.
+type NotificationKind string
+
+const (
+	NotificationPodFailed       NotificationKind = "PodFailed"
+	NotificationPodSucceeded    NotificationKind = "PodSucceeded"
+	NotificationNodeNotReady    NotificationKind = "NodeNotReady"
+	NotificationWarning         NotificationKind = "Warning"
+	NotificationDeployCompleted NotificationKind = "DeployCompleted"
+)
+
+// Notification describes a cluster occurrence worth telling people about.
+type Notification struct {
+	Kind      NotificationKind `json:"kind"`
+	Namespace string           `json:"namespace,omitempty"`
+	// Object is the kind/name of the object the notification is about.
+	Object  string `json:"object"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message"`
+}
+
+func (n Notification) String() string {
+	if n.Namespace == "" {
+		return fmt.Sprintf("[%s] %s: %s", n.Kind, n.Object, n.Message)
+	}
+	return fmt.Sprintf("[%s] %s/%s: %s", n.Kind, n.Namespace, n.Object, n.Message)
+}
+
+// Notifier delivers notifications, e.g. to a chat channel.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// WebhookNotifier POSTs each notification as JSON to URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification in json: %v", err)
+	}
+	return postNotification(ctx, w.Client, w.URL, body)
+}
+
+// SlackNotifier posts each notification as a message to a Slack incoming
+// webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(map[string]string{"text": n.String()})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification in json: %v", err)
+	}
+	return postNotification(ctx, s.Client, s.WebhookURL, body)
+}
+
+func postNotification(ctx context.Context, httpClient *http.Client, url string, body []byte) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: POST %q : %v", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := doRequest(ctx, httpClient, req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: POST %q: %v", url, err)
+	}
+	resBody, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("http error: %d POST %q: %q", res.StatusCode, url, string(resBody))
+	}
+	return nil
+}
+
+// NotifyWarnings sends a notification for every Warning event recorded in
+// namespace (all namespaces if empty) from now until ctx is done or the
+// watch fails; events recorded before it starts are not sent. Node NotReady
+// events, which are Normal events, are reported as
+// NotificationNodeNotReady. Failures to deliver a notification are passed
+// to onError, which may be nil, and do not stop the watch.
+func (c *Client) NotifyWarnings(ctx context.Context, namespace string, notifier Notifier, onError func(error)) error {
+	apiResult, err := ListKubeResources(ctx, &EventResource{c.Host, namespace, ""}, c.Client)
+	if err != nil {
+		return fmt.Errorf("Resource List failed: %w", err)
+	}
+	events, err := c.watchEventsFrom(ctx, namespace, "", objectResourceVersion(apiResult), SeverityNormal)
+	if err != nil {
+		return err
+	}
+	for er := range events {
+		if er.Err != nil {
+			return er.Err
+		}
+		if er.Event == nil {
+			continue
+		}
+		e := er.Event
+		nodeNotReady := e.InvolvedObject.Kind == "Node" && e.Reason == "NodeNotReady"
+		if er.Type != "Warning" && !nodeNotReady {
+			continue
+		}
+		n := Notification{
+			Kind:      NotificationWarning,
+			Namespace: e.InvolvedObject.Namespace,
+			Object:    e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name,
+			Reason:    e.Reason,
+			Message:   e.Message,
+		}
+		if nodeNotReady {
+			n.Kind = NotificationNodeNotReady
+		}
+		if err := notifier.Notify(ctx, n); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	return nil
+}
+
+// NotifyPodCompletion watches the pod and sends a notification when it
+// fails or succeeds, then returns. An error is returned if the watch fails
+// or ctx is done first.
+func (c *Client) NotifyPodCompletion(ctx context.Context, namespace, podName, podResourceVersion string, notifier Notifier) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	podStatusResult, err := c.WatchPod(ctx, namespace, podName, podResourceVersion)
+	if err != nil {
+		return err
+	}
+	for psr := range podStatusResult {
+		if psr.Err != nil {
+			return psr.Err
+		}
+		n := Notification{
+			Namespace: namespace,
+			Object:    "Pod/" + podName,
+			Reason:    psr.Pod.Status.Reason,
+			Message:   psr.Pod.Status.Message,
+		}
+		switch psr.Pod.Status.Phase {
+		case api.PodFailed:
+			n.Kind = NotificationPodFailed
+		case api.PodSucceeded:
+			n.Kind = NotificationPodSucceeded
+		default:
+			continue
+		}
+		if n.Message == "" {
+			n.Message = "pod " + string(psr.Pod.Status.Phase)
+		}
+		return notifier.Notify(ctx, n)
+	}
+	return nil
+}
+
+// NotifyDeployCompletion polls the deployment every interval, 2 seconds if
+// zero, until DeploymentAvailable reports it rolled out, then sends a
+// NotificationDeployCompleted and returns. An error is returned if polling
+// fails or ctx is done first, in which case nothing is sent.
+func (c *Client) NotifyDeployCompletion(ctx context.Context, namespace, name string, interval time.Duration, notifier Notifier) error {
+	if interval <= 0 {
+		interval = defaultMigratePollInterval
+	}
+	deployment, err := c.awaitDeploymentAvailable(ctx, namespace, name, interval)
+	if err != nil {
+		return err
+	}
+	return notifier.Notify(ctx, Notification{
+		Kind:      NotificationDeployCompleted,
+		Namespace: namespace,
+		Object:    "Deployment/" + name,
+		Message:   fmt.Sprintf("deployment available with %d updated replicas", deployment.Status.UpdatedReplicas),
+	})
+}
+
+// NotifyReplicationControllerCompletion is NotifyDeployCompletion for a
+// replication controller, which is complete once the controller has acted
+// on its latest spec and as many of its pods as it wants are ready.
+func (c *Client) NotifyReplicationControllerCompletion(ctx context.Context, namespace, name string, interval time.Duration, notifier Notifier) error {
+	if interval <= 0 {
+		interval = defaultMigratePollInterval
+	}
+	rc, err := c.awaitReplicationControllerAvailable(ctx, namespace, name, interval)
+	if err != nil {
+		return err
+	}
+	return notifier.Notify(ctx, Notification{
+		Kind:      NotificationDeployCompleted,
+		Namespace: namespace,
+		Object:    "ReplicationController/" + name,
+		Message:   fmt.Sprintf("replication controller available with %d ready replicas", rc.Spec.Replicas),
+	})
+}
+
+// awaitReplicationControllerAvailable polls the replication controller
+// until it has observed its latest spec and created its replicas, then waits
+// for that many of its pods to be ready, or until ctx is done.
+func (c *Client) awaitReplicationControllerAvailable(ctx context.Context, namespace, name string, interval time.Duration) (*api.ReplicationController, error) {
+	ticker := c.clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		rc, err := c.GetReplicationController(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if rc.Status.ObservedGeneration >= rc.Generation && rc.Status.Replicas == rc.Spec.Replicas {
+			selector := rc.Spec.Selector
+			if len(selector) == 0 && rc.Spec.Template != nil {
+				selector = rc.Spec.Template.Labels
+			}
+			if err := c.awaitReadyPods(ctx, namespace, selector, rc.Spec.Replicas, interval); err != nil {
+				return nil, err
+			}
+			return rc, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("replication controller %s did not become available: %v", name, ctx.Err())
+		case <-ticker.C():
+		}
+	}
+}
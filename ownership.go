@@ -0,0 +1,168 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// OwnerReference identifies the controller that owns an object, mirroring
+// metadata.ownerReferences, which the api package predates.
+type OwnerReference struct {
+	APIVersion         string `json:"apiVersion"`
+	Kind               string `json:"kind"`
+	Name               string `json:"name"`
+	UID                string `json:"uid"`
+	Controller         *bool  `json:"controller,omitempty"`
+	BlockOwnerDeletion *bool  `json:"blockOwnerDeletion,omitempty"`
+}
+
+// ReplicationControllerOwner returns the controller reference that pods
+// owned by rc carry.
+func ReplicationControllerOwner(rc *api.ReplicationController) OwnerReference {
+	return ControllerReference("v1", "ReplicationController", rc.Name, rc.UID)
+}
+
+// ownedObject holds the metadata needed to change an object's owners.
+type ownedObject struct {
+	Metadata struct {
+		Name            string            `json:"name"`
+		ResourceVersion string            `json:"resourceVersion"`
+		Labels          map[string]string `json:"labels"`
+		OwnerReferences []OwnerReference  `json:"ownerReferences"`
+	} `json:"metadata"`
+}
+
+func (o *ownedObject) controller() *OwnerReference {
+	for i, ref := range o.Metadata.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return &o.Metadata.OwnerReferences[i]
+		}
+	}
+	return nil
+}
+
+func (c *Client) getOwnedPod(ctx context.Context, namespace, podName string) (*ownedObject, error) {
+	apiResult, err := GetKubeResource(ctx, c.podURL(namespace, podName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var pod ownedObject
+	if err := json.Unmarshal(apiResult, &pod); err != nil {
+		return nil, fmt.Errorf("failed to decode pod json: %v", err)
+	}
+	return &pod, nil
+}
+
+// AdoptPod makes owner the controller of the pod and sets labels on it, the
+// way a controller adopts a matching orphan. It fails if the pod already has
+// a different controller. The patch is conditional on the pod's
+// resourceVersion, so concurrent changes cause a conflict error rather than
+// being overwritten.
+func (c *Client) AdoptPod(ctx context.Context, namespace, podName string, owner OwnerReference, labels map[string]string) error {
+	pod, err := c.getOwnedPod(ctx, namespace, podName)
+	if err != nil {
+		return err
+	}
+	refs := pod.Metadata.OwnerReferences
+	if ctrl := pod.controller(); ctrl != nil {
+		if ctrl.UID != owner.UID {
+			return fmt.Errorf("pod %s is already controlled by %s %s", podName, ctrl.Kind, ctrl.Name)
+		}
+	} else {
+		refs = append(refs, owner)
+	}
+
+	metadata := map[string]interface{}{
+		"resourceVersion": pod.Metadata.ResourceVersion,
+		"ownerReferences": refs,
+	}
+	// A null labels field in a merge patch would delete every label.
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	patch := map[string]interface{}{"metadata": metadata}
+	_, err = mergePatchKubeResource(ctx, c.podURL(namespace, podName), patch, c.Client)
+	return err
+}
+
+// OrphanPod removes owner from the pod's owner references and removes the
+// given labels, so that the owner's selector no longer matches and it does
+// not adopt the pod again.
+func (c *Client) OrphanPod(ctx context.Context, namespace, podName string, owner OwnerReference, removeLabels []string) error {
+	pod, err := c.getOwnedPod(ctx, namespace, podName)
+	if err != nil {
+		return err
+	}
+	refs := []OwnerReference{}
+	for _, ref := range pod.Metadata.OwnerReferences {
+		if ref.UID != owner.UID {
+			refs = append(refs, ref)
+		}
+	}
+	// In a merge patch, null deletes a key.
+	labels := make(map[string]interface{}, len(removeLabels))
+	for _, l := range removeLabels {
+		labels[l] = nil
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": pod.Metadata.ResourceVersion,
+			"labels":          labels,
+			"ownerReferences": refs,
+		},
+	}
+	_, err = mergePatchKubeResource(ctx, c.podURL(namespace, podName), patch, c.Client)
+	return err
+}
+
+// AdoptOrphanedPods adopts every pod in the replication controller's
+// namespace that matches its selector and has no controller, and returns the
+// names of the pods adopted. Pods that fail to be adopted are reported
+// together in an AggregateError.
+func (c *Client) AdoptOrphanedPods(ctx context.Context, rc *api.ReplicationController) ([]string, error) {
+	apiResult, err := ListKubeResources(ctx, &PodResource{c.Host, rc.Namespace, selectorString(rc.Spec.Selector)}, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var pods struct {
+		Items []ownedObject `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &pods); err != nil {
+		return nil, fmt.Errorf("failed to decode pod resources: %v", err)
+	}
+
+	owner := ReplicationControllerOwner(rc)
+	var adopted []string
+	var failed AggregateError
+	for _, pod := range pods.Items {
+		if pod.controller() != nil {
+			continue
+		}
+		if err := c.AdoptPod(ctx, rc.Namespace, pod.Metadata.Name, owner, nil); err != nil {
+			failed.add("adopt", api.ObjectReference{Kind: "Pod", Namespace: rc.Namespace, Name: pod.Metadata.Name}, err)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		adopted = append(adopted, pod.Metadata.Name)
+	}
+	return adopted, failed.err()
+}
+
+// selectorString renders an equality based selector as a label selector
+// string, with its terms sorted.
+func selectorString(selector map[string]string) string {
+	terms := make([]string, 0, len(selector))
+	for k, v := range selector {
+		terms = append(terms, k+"="+v)
+	}
+	sort.Strings(terms)
+	return strings.Join(terms, ",")
+}
@@ -0,0 +1,21 @@
+package kubeclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrPartial is returned, along with the results gathered so far, by
+// aggregate operations such as Inventory when their context is done before
+// they finish, so that callers can show what was gathered rather than
+// nothing.
+type ErrPartial struct {
+	// Missing names the parts that were not gathered, e.g. "secrets".
+	Missing []string
+	// Err is the context's error.
+	Err error
+}
+
+func (e *ErrPartial) Error() string {
+	return fmt.Sprintf("partial results, missing %s: %v", strings.Join(e.Missing, ", "), e.Err)
+}
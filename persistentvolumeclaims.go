@@ -0,0 +1,208 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	persistentVolumeClaimsPath = apiPrefix + "/namespaces/%s/persistentvolumeclaims"
+	persistentVolumeClaimPath  = apiPrefix + "/namespaces/%s/persistentvolumeclaims/%s"
+)
+
+// Access modes of persistent volumes and claims.
+const (
+	ReadWriteOnce    = "ReadWriteOnce"
+	ReadOnlyMany     = "ReadOnlyMany"
+	ReadWriteMany    = "ReadWriteMany"
+	ReadWriteOncePod = "ReadWriteOncePod"
+)
+
+// PersistentVolumeClaim is a request for storage. The api package's claim
+// lacks the storage class and selector, so it is not used.
+type PersistentVolumeClaim struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Spec           PersistentVolumeClaimSpec   `json:"spec,omitempty"`
+	Status         PersistentVolumeClaimStatus `json:"status,omitempty"`
+}
+
+type PersistentVolumeClaimSpec struct {
+	AccessModes []string `json:"accessModes,omitempty"`
+	// Resources holds the storage requested, e.g.
+	// {"requests": {"storage": "10Gi"}}.
+	Resources map[string]map[string]string `json:"resources,omitempty"`
+	// StorageClassName selects the class to provision from; nil uses the
+	// cluster's default class, and "" asks for a volume without a class.
+	StorageClassName *string        `json:"storageClassName,omitempty"`
+	Selector         *LabelSelector `json:"selector,omitempty"`
+	// VolumeName binds the claim to the named persistent volume.
+	VolumeName string `json:"volumeName,omitempty"`
+	// VolumeMode is "Filesystem", the default, or "Block".
+	VolumeMode *string `json:"volumeMode,omitempty"`
+}
+
+type PersistentVolumeClaimStatus struct {
+	// Phase is "Pending", "Bound", or "Lost".
+	Phase       api.PersistentVolumeClaimPhase `json:"phase,omitempty"`
+	AccessModes []string                       `json:"accessModes,omitempty"`
+	Capacity    map[string]string              `json:"capacity,omitempty"`
+}
+
+// ClaimLost is the phase of a claim whose volume was deleted from under it.
+const ClaimLost api.PersistentVolumeClaimPhase = "Lost"
+
+type PersistentVolumeClaimResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (pvc *PersistentVolumeClaimResource) KubeResourcesURL() string {
+	return pvc.Host + fmt.Sprintf(persistentVolumeClaimsPath, pvc.Namespace)
+}
+
+func (pvc *PersistentVolumeClaimResource) KubeResourceNamespace() string {
+	return pvc.Namespace
+}
+
+func (pvc *PersistentVolumeClaimResource) KubeResourceLabel() string {
+	return pvc.Label
+}
+
+func (c *Client) CreatePersistentVolumeClaim(ctx context.Context, claim *PersistentVolumeClaim) (*PersistentVolumeClaim, error) {
+	var claimJSON bytes.Buffer
+	if err := json.NewEncoder(&claimJSON).Encode(claim); err != nil {
+		return nil, fmt.Errorf("failed to encode persistent volume claim in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", claim.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &PersistentVolumeClaimResource{c.Host, namespace, ""}, claimJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.persistentVolumeClaimURL(namespace, claim.Name), claim)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var claimResult PersistentVolumeClaim
+	if err := json.Unmarshal(apiResult, &claimResult); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume claim resources: %v", err)
+	}
+	return &claimResult, nil
+}
+
+func (c *Client) GetPersistentVolumeClaim(ctx context.Context, namespace, name string) (*PersistentVolumeClaim, error) {
+	apiResult, err := GetKubeResource(ctx, c.persistentVolumeClaimURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var claim PersistentVolumeClaim
+	if err := json.Unmarshal(apiResult, &claim); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume claim json: %v", err)
+	}
+	return &claim, nil
+}
+
+// UpdatePersistentVolumeClaim replaces the claim. Only its storage request,
+// which can grow if its class allows expansion, and its metadata may
+// change. Its ResourceVersion guards against overwriting concurrent
+// changes.
+func (c *Client) UpdatePersistentVolumeClaim(ctx context.Context, claim *PersistentVolumeClaim) (*PersistentVolumeClaim, error) {
+	var claimJSON bytes.Buffer
+	if err := json.NewEncoder(&claimJSON).Encode(claim); err != nil {
+		return nil, fmt.Errorf("failed to encode persistent volume claim in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", claim.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	url := c.persistentVolumeClaimURL(namespace, claim.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, claimJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, claim)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var claimResult PersistentVolumeClaim
+	if err := json.Unmarshal(apiResult, &claimResult); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume claim resources: %v", err)
+	}
+	return &claimResult, nil
+}
+
+// DeletePersistentVolumeClaim deletes the claim. Its volume is deleted or
+// kept according to the volume's reclaim policy.
+func (c *Client) DeletePersistentVolumeClaim(ctx context.Context, namespace, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.persistentVolumeClaimURL(namespace, name), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) PersistentVolumeClaimList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]PersistentVolumeClaim, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &PersistentVolumeClaimResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var claimList struct {
+		Items []PersistentVolumeClaim `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &claimList); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume claim resources: %v", err)
+	}
+	return claimList.Items, nil
+}
+
+// AwaitPVCBound watches the claim until it is bound to a volume, and
+// returns it. Claims of a class with WaitForFirstConsumer volume binding
+// are only bound once a pod using them is scheduled, so create the pod
+// before waiting on those. It fails if the claim is lost or deleted, or
+// if ctx is done first.
+func (c *Client) AwaitPVCBound(ctx context.Context, namespace, name string) (*PersistentVolumeClaim, error) {
+	values := url.Values{}
+	values.Set("fieldSelector", "metadata.name="+name)
+	backoff := watchBackoff{clock: c.clock()}
+	for {
+		claim, err := c.GetPersistentVolumeClaim(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		var done *PersistentVolumeClaim
+		if claim.Status.Phase == api.ClaimBound || claim.Status.Phase == ClaimLost {
+			done = claim
+		} else {
+			err = watchKubeResourcesQuery(ctx, &PersistentVolumeClaimResource{c.Host, namespace, ""}, values, claim.ResourceVersion, c.Client, func(eventType string, object json.RawMessage) error {
+				var watched PersistentVolumeClaim
+				if err := json.Unmarshal(object, &watched); err != nil {
+					return fmt.Errorf("failed to decode watched persistent volume claim: %v", err)
+				}
+				backoff.reset()
+				if eventType == "DELETED" {
+					return fmt.Errorf("persistent volume claim %s was deleted before it was bound", name)
+				}
+				if watched.Status.Phase == api.ClaimBound || watched.Status.Phase == ClaimLost {
+					done = &watched
+					return errWatchDone
+				}
+				return nil
+			})
+		}
+		if done != nil {
+			if done.Status.Phase == ClaimLost {
+				return done, fmt.Errorf("persistent volume claim %s lost its volume %s", name, done.Spec.VolumeName)
+			}
+			return done, nil
+		}
+		if err != ErrWatchGone && err != ErrWatchClosed {
+			return nil, err
+		}
+		if err := backoff.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) persistentVolumeClaimURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(persistentVolumeClaimPath, namespace, name)
+}
@@ -0,0 +1,174 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	persistentVolumesPath = apiPrefix + "/persistentvolumes"
+	persistentVolumePath  = apiPrefix + "/persistentvolumes/%s"
+)
+
+// Reclaim policies of persistent volumes.
+const (
+	// ReclaimRetain keeps a volume, and its data, once its claim is
+	// deleted, for an administrator to reclaim by hand.
+	ReclaimRetain = "Retain"
+	// ReclaimDelete deletes a volume along with its claim.
+	ReclaimDelete = "Delete"
+)
+
+// PersistentVolume is a piece of storage in the cluster, provisioned by an
+// administrator or dynamically for a claim. The api package predates it.
+// Only the common volume sources are modeled; updating a volume with
+// another source through this client drops it.
+type PersistentVolume struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Spec           PersistentVolumeSpec   `json:"spec,omitempty"`
+	Status         PersistentVolumeStatus `json:"status,omitempty"`
+}
+
+type PersistentVolumeSpec struct {
+	// Capacity holds the volume's size, e.g. {"storage": "10Gi"}.
+	Capacity    map[string]string `json:"capacity,omitempty"`
+	AccessModes []string          `json:"accessModes,omitempty"`
+	// PersistentVolumeReclaimPolicy is ReclaimRetain or ReclaimDelete.
+	PersistentVolumeReclaimPolicy string `json:"persistentVolumeReclaimPolicy,omitempty"`
+	StorageClassName              string `json:"storageClassName,omitempty"`
+	// ClaimRef is the claim the volume is bound, or reserved, to.
+	ClaimRef     *api.ObjectReference `json:"claimRef,omitempty"`
+	MountOptions []string             `json:"mountOptions,omitempty"`
+	VolumeMode   *string              `json:"volumeMode,omitempty"`
+	// NodeAffinity limits the nodes the volume can be used from, as local
+	// volumes require.
+	NodeAffinity json.RawMessage `json:"nodeAffinity,omitempty"`
+
+	HostPath *api.HostPathVolumeSource `json:"hostPath,omitempty"`
+	Local    *LocalVolumeSource        `json:"local,omitempty"`
+	NFS      *NFSVolumeSource          `json:"nfs,omitempty"`
+	CSI      *CSIVolumeSource          `json:"csi,omitempty"`
+}
+
+type LocalVolumeSource struct {
+	Path   string `json:"path"`
+	FSType string `json:"fsType,omitempty"`
+}
+
+type NFSVolumeSource struct {
+	Server   string `json:"server"`
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+}
+
+type CSIVolumeSource struct {
+	Driver           string            `json:"driver"`
+	VolumeHandle     string            `json:"volumeHandle"`
+	ReadOnly         bool              `json:"readOnly,omitempty"`
+	FSType           string            `json:"fsType,omitempty"`
+	VolumeAttributes map[string]string `json:"volumeAttributes,omitempty"`
+}
+
+type PersistentVolumeStatus struct {
+	// Phase is "Pending", "Available", "Bound", "Released", or "Failed".
+	Phase   string `json:"phase,omitempty"`
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type PersistentVolumeResource struct {
+	Host  string
+	Label string
+}
+
+func (pv *PersistentVolumeResource) KubeResourcesURL() string {
+	return pv.Host + persistentVolumesPath
+}
+
+// KubeResourceNamespace returns "" since persistent volumes are
+// cluster-scoped.
+func (pv *PersistentVolumeResource) KubeResourceNamespace() string {
+	return ""
+}
+
+func (pv *PersistentVolumeResource) KubeResourceLabel() string {
+	return pv.Label
+}
+
+func (c *Client) CreatePersistentVolume(ctx context.Context, volume *PersistentVolume) (*PersistentVolume, error) {
+	var volumeJSON bytes.Buffer
+	if err := json.NewEncoder(&volumeJSON).Encode(volume); err != nil {
+		return nil, fmt.Errorf("failed to encode persistent volume in json: %v", err)
+	}
+	apiResult, err := CreateKubeResource(ctx, &PersistentVolumeResource{c.Host, ""}, volumeJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.persistentVolumeURL(volume.Name), volume)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var volumeResult PersistentVolume
+	if err := json.Unmarshal(apiResult, &volumeResult); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume resources: %v", err)
+	}
+	return &volumeResult, nil
+}
+
+func (c *Client) GetPersistentVolume(ctx context.Context, name string) (*PersistentVolume, error) {
+	apiResult, err := GetKubeResource(ctx, c.persistentVolumeURL(name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var volume PersistentVolume
+	if err := json.Unmarshal(apiResult, &volume); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume json: %v", err)
+	}
+	return &volume, nil
+}
+
+// UpdatePersistentVolume replaces the persistent volume. Its
+// ResourceVersion guards against overwriting concurrent changes.
+func (c *Client) UpdatePersistentVolume(ctx context.Context, volume *PersistentVolume) (*PersistentVolume, error) {
+	var volumeJSON bytes.Buffer
+	if err := json.NewEncoder(&volumeJSON).Encode(volume); err != nil {
+		return nil, fmt.Errorf("failed to encode persistent volume in json: %v", err)
+	}
+	url := c.persistentVolumeURL(volume.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, volumeJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, volume)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var volumeResult PersistentVolume
+	if err := json.Unmarshal(apiResult, &volumeResult); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume resources: %v", err)
+	}
+	return &volumeResult, nil
+}
+
+// DeletePersistentVolume deletes the persistent volume. A volume still
+// bound to a claim is only removed once the claim is deleted.
+func (c *Client) DeletePersistentVolume(ctx context.Context, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.persistentVolumeURL(name), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) PersistentVolumeList(ctx context.Context, label string, opts ...ListOptions) ([]PersistentVolume, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &PersistentVolumeResource{c.Host, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var volumeList struct {
+		Items []PersistentVolume `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &volumeList); err != nil {
+		return nil, fmt.Errorf("failed to decode persistent volume resources: %v", err)
+	}
+	return volumeList.Items, nil
+}
+
+func (c *Client) persistentVolumeURL(name string) string {
+	return c.Host + fmt.Sprintf(persistentVolumePath, name)
+}
@@ -0,0 +1,99 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/wearemolecule/kubeclient/labels"
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// PlacementReport describes how a set of pods is spread across the
+// cluster's nodes and topology zones, for availability reviews.
+type PlacementReport struct {
+	// Nodes holds the names of the pods on each node, sorted.
+	Nodes map[string][]string
+	// Zones counts the pods in each zone. Pods on nodes without a zone
+	// label are counted under "".
+	Zones map[string]int
+	// Unscheduled holds the names of the pods not yet placed on a node,
+	// sorted.
+	Unscheduled []string
+	// ClusterZones are the zones of the cluster's schedulable nodes,
+	// sorted.
+	ClusterZones []string
+	// SingleZone is set when more than one pod is placed and they all
+	// share a zone, though the cluster has schedulable nodes in others,
+	// so losing that zone would take all of them down.
+	SingleZone bool
+	// SingleNode is set when more than one pod is placed and they all
+	// share a node.
+	SingleNode bool
+}
+
+// PlacementReport reports how the running and pending pods in namespace
+// matching selector are spread across nodes and zones, flagging when they
+// are concentrated in a single zone or node. A nil selector matches every
+// pod in namespace.
+func (c *Client) PlacementReport(ctx context.Context, namespace string, selector *Selector) (*PlacementReport, error) {
+	apiResult, err := ListKubeResources(ctx, WithSelector(&PodResource{c.Host, namespace, ""}, selector), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var podList api.PodList
+	if err := json.Unmarshal(apiResult, &podList); err != nil {
+		return nil, fmt.Errorf("failed to decode pod resources: %v", err)
+	}
+	nodes, err := c.NodeList(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	nodeZones := make(map[string]string, len(nodes))
+	clusterZones := make(map[string]bool)
+	for _, node := range nodes {
+		zone := labels.Zone(node.Labels)
+		nodeZones[node.Name] = zone
+		if !node.Spec.Unschedulable && zone != "" {
+			clusterZones[zone] = true
+		}
+	}
+
+	report := &PlacementReport{
+		Nodes: make(map[string][]string),
+		Zones: make(map[string]int),
+	}
+	placed := 0
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == api.PodSucceeded || pod.Status.Phase == api.PodFailed {
+			continue
+		}
+		if pod.Spec.NodeName == "" {
+			report.Unscheduled = append(report.Unscheduled, pod.Name)
+			continue
+		}
+		placed++
+		report.Nodes[pod.Spec.NodeName] = append(report.Nodes[pod.Spec.NodeName], pod.Name)
+		report.Zones[nodeZones[pod.Spec.NodeName]]++
+	}
+	for _, names := range report.Nodes {
+		sort.Strings(names)
+	}
+	sort.Strings(report.Unscheduled)
+	for zone := range clusterZones {
+		report.ClusterZones = append(report.ClusterZones, zone)
+	}
+	sort.Strings(report.ClusterZones)
+
+	if placed > 1 {
+		report.SingleNode = len(report.Nodes) == 1
+		if len(report.Zones) == 1 && len(clusterZones) > 1 {
+			for zone := range report.Zones {
+				report.SingleZone = zone != ""
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,127 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// conditionedPod is the part of a pod that SetPodCondition and
+// PodReadinessGates need. Conditions are decoded generically, so fields
+// this package does not know about survive being written back.
+type conditionedPod struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Spec struct {
+		ReadinessGates []struct {
+			ConditionType api.PodConditionType `json:"conditionType"`
+		} `json:"readinessGates"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []map[string]interface{} `json:"conditions"`
+	} `json:"status"`
+}
+
+// SetPodCondition sets the condition of type cond.Type in the pod's status,
+// replacing the existing condition of that type or adding one. This is how
+// a controller satisfies a custom readiness gate declared in the pod's
+// spec: the kubelet only reports the pod Ready once every gate's condition
+// is True. If cond.LastTransitionTime is zero, it is set to now when the
+// condition's status changes and kept otherwise. The status is patched
+// conditional on the pod's resourceVersion, and refetched if the pod
+// changed meanwhile.
+func (c *Client) SetPodCondition(ctx context.Context, namespace, podName string, cond api.PodCondition) error {
+	return retryOnConflict(ctx, c.clock(), nil, func() error {
+		pod, err := c.getConditionedPod(ctx, namespace, podName)
+		if err != nil {
+			return err
+		}
+		conditions := pod.Status.Conditions
+		i := 0
+		for i < len(conditions) && conditions[i]["type"] != string(cond.Type) {
+			i++
+		}
+		transition := cond.LastTransitionTime.Time
+		if transition.IsZero() {
+			transition = c.clock().Now()
+			if i < len(conditions) && conditions[i]["status"] == string(cond.Status) {
+				if s, ok := conditions[i]["lastTransitionTime"].(string); ok {
+					if t, err := time.Parse(time.RFC3339, s); err == nil {
+						transition = t
+					}
+				}
+			}
+		}
+		condition := map[string]interface{}{
+			"type":               cond.Type,
+			"status":             cond.Status,
+			"lastTransitionTime": transition.UTC().Format(time.RFC3339),
+		}
+		if !cond.LastProbeTime.IsZero() {
+			condition["lastProbeTime"] = cond.LastProbeTime.UTC().Format(time.RFC3339)
+		}
+		if cond.Reason != "" {
+			condition["reason"] = cond.Reason
+		}
+		if cond.Message != "" {
+			condition["message"] = cond.Message
+		}
+		if i < len(conditions) {
+			conditions[i] = condition
+		} else {
+			conditions = append(conditions, condition)
+		}
+
+		// A merge patch replaces the whole list, so the resourceVersion
+		// guards the other conditions against concurrent writers.
+		patch := map[string]interface{}{
+			"metadata": map[string]interface{}{"resourceVersion": pod.Metadata.ResourceVersion},
+			"status":   map[string]interface{}{"conditions": conditions},
+		}
+		if _, err := mergePatchKubeResource(ctx, c.podURL(namespace, podName)+"/status", patch, c.Client); err != nil {
+			return fmt.Errorf("Update failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// PodReadinessGates returns the condition types listed as readiness gates
+// in the pod's spec.
+func (c *Client) PodReadinessGates(ctx context.Context, namespace, podName string) ([]api.PodConditionType, error) {
+	pod, err := c.getConditionedPod(ctx, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	var gates []api.PodConditionType
+	for _, gate := range pod.Spec.ReadinessGates {
+		gates = append(gates, gate.ConditionType)
+	}
+	return gates, nil
+}
+
+// GetPodCondition returns the condition of type t in the pod's status, or
+// nil if it has none.
+func GetPodCondition(pod *api.Pod, t api.PodConditionType) *api.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == t {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func (c *Client) getConditionedPod(ctx context.Context, namespace, podName string) (*conditionedPod, error) {
+	apiResult, err := GetKubeResource(ctx, c.podURL(namespace, podName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var pod conditionedPod
+	if err := json.Unmarshal(apiResult, &pod); err != nil {
+		return nil, fmt.Errorf("failed to decode pod json: %v", err)
+	}
+	return &pod, nil
+}
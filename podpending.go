@@ -0,0 +1,92 @@
+package kubeclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// podPendingEvents is how many of a pod's most recent events a
+// PodPendingError keeps.
+const podPendingEvents = 10
+
+// PodPendingError is returned by CreatePod when the pod it created did not
+// leave the Pending phase in time. It carries what usually explains why:
+// the pod's conditions, such as PodScheduled being False because no node
+// fits, the reasons its containers are waiting, such as ImagePullBackOff,
+// and its recent events, such as FailedScheduling.
+type PodPendingError struct {
+	Namespace string
+	Pod       string
+	// Err is why the wait ended, e.g. context.DeadlineExceeded.
+	Err        error
+	Conditions []api.PodCondition
+	// Waiting holds the state of each container still waiting to start,
+	// by container name.
+	Waiting map[string]api.ContainerStateWaiting
+	// Events are the pod's most recent events, oldest first.
+	Events []api.Event
+}
+
+func (e *PodPendingError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pod %s/%s did not leave the Pending phase: %v", e.Namespace, e.Pod, e.Err)
+	for _, cond := range e.Conditions {
+		if cond.Status == api.ConditionTrue {
+			continue
+		}
+		fmt.Fprintf(&b, "; %s=%s", cond.Type, cond.Status)
+		if cond.Reason != "" {
+			fmt.Fprintf(&b, " %s: %s", cond.Reason, cond.Message)
+		}
+	}
+	for name, waiting := range e.Waiting {
+		fmt.Fprintf(&b, "; container %s waiting: %s", name, waiting.Reason)
+		if waiting.Message != "" {
+			fmt.Fprintf(&b, ": %s", waiting.Message)
+		}
+	}
+	for _, event := range e.Events {
+		fmt.Fprintf(&b, "; event %s: %s", event.Reason, event.Message)
+	}
+	return b.String()
+}
+
+func (e *PodPendingError) Unwrap() error {
+	return e.Err
+}
+
+// podPendingError gathers the diagnostics of a PodPendingError for the pod,
+// whose wait ended with err. Diagnostics that cannot be fetched are left
+// out.
+func (c *Client) podPendingError(namespace, podName string, err error) *PodPendingError {
+	var eventsErr *PodEventsError
+	if errors.As(err, &eventsErr) {
+		err = eventsErr.Err
+	}
+	pending := &PodPendingError{Namespace: namespace, Pod: podName, Err: err}
+
+	ctx, cancel := withTimeout(context.Background(), c.clock(), podEventsTimeout)
+	defer cancel()
+	if pod, err := c.GetPod(ctx, namespace, podName); err == nil {
+		pending.Conditions = pod.Status.Conditions
+		for _, status := range pod.Status.ContainerStatuses {
+			if waiting := status.State.Waiting; waiting != nil && waiting.Reason != "" {
+				if pending.Waiting == nil {
+					pending.Waiting = make(map[string]api.ContainerStateWaiting)
+				}
+				pending.Waiting[status.Name] = *waiting
+			}
+		}
+	}
+	if events, err := c.ObjectEvents(ctx, namespace, "Pod", podName); err == nil {
+		if len(events) > podPendingEvents {
+			events = events[len(events)-podPendingEvents:]
+		}
+		pending.Events = events
+	}
+	return pending
+}
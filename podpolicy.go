@@ -0,0 +1,119 @@
+package kubeclient
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+)
+
+// PodPolicy is a client-side baseline security policy for pods. When set
+// on Client.PodPolicy, CreatePod rejects pods that violate it with a
+// *PolicyError before the request is sent.
+type PodPolicy struct {
+	// RequireRunAsNonRoot requires runAsNonRoot to be set for every
+	// container, either on the container or the pod security context.
+	RequireRunAsNonRoot bool
+	// ForbidPrivileged rejects privileged containers.
+	ForbidPrivileged bool
+	// ForbidHostPath rejects hostPath volumes.
+	ForbidHostPath bool
+	// RequiredLimits lists the resources, such as api.ResourceCPU and
+	// api.ResourceMemory, every container must set a limit for.
+	RequiredLimits []api.ResourceName
+}
+
+// Violation is a single way in which a pod breaks a PodPolicy.
+type Violation struct {
+	// Field is the path of the offending field, e.g.
+	// "spec.containers[0].securityContext.privileged".
+	Field   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// PolicyError is returned for pods that violate a PodPolicy.
+type PolicyError struct {
+	Pod        string
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("pod %s violates policy: %s", e.Pod, strings.Join(msgs, "; "))
+}
+
+// Validate returns the ways in which pod violates the policy, or nil if it
+// complies.
+func (p *PodPolicy) Validate(pod *api.Pod) []Violation {
+	var violations []Violation
+	spec := &pod.Spec
+
+	podNonRoot := spec.SecurityContext != nil && spec.SecurityContext.RunAsNonRoot != nil && *spec.SecurityContext.RunAsNonRoot
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		field := fmt.Sprintf("spec.containers[%d]", i)
+		sc := container.SecurityContext
+
+		if p.RequireRunAsNonRoot {
+			nonRoot := podNonRoot
+			if sc != nil && sc.RunAsNonRoot != nil {
+				nonRoot = *sc.RunAsNonRoot
+			}
+			if !nonRoot {
+				violations = append(violations, Violation{
+					Field:   field + ".securityContext.runAsNonRoot",
+					Message: fmt.Sprintf("container %q must run as non-root", container.Name),
+				})
+			}
+		}
+		if p.ForbidPrivileged && sc != nil && sc.Privileged != nil && *sc.Privileged {
+			violations = append(violations, Violation{
+				Field:   field + ".securityContext.privileged",
+				Message: fmt.Sprintf("container %q must not be privileged", container.Name),
+			})
+		}
+		for _, resource := range p.RequiredLimits {
+			if _, ok := container.Resources.Limits[resource]; !ok {
+				violations = append(violations, Violation{
+					Field:   fmt.Sprintf("%s.resources.limits.%s", field, resource),
+					Message: fmt.Sprintf("container %q must set a %s limit", container.Name, resource),
+				})
+			}
+		}
+	}
+
+	if p.ForbidHostPath {
+		for i, volume := range spec.Volumes {
+			if volume.HostPath != nil {
+				violations = append(violations, Violation{
+					Field:   fmt.Sprintf("spec.volumes[%d].hostPath", i),
+					Message: fmt.Sprintf("volume %q must not use a hostPath", volume.Name),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// checkPodPolicy returns a *PolicyError if c.PodPolicy is set and pod
+// violates it.
+func (c *Client) checkPodPolicy(pod *api.Pod) error {
+	if c.PodPolicy == nil {
+		return nil
+	}
+	if violations := c.PodPolicy.Validate(pod); len(violations) > 0 {
+		name := pod.Name
+		if name == "" {
+			name = pod.GenerateName
+		}
+		return &PolicyError{Pod: name, Violations: violations}
+	}
+	return nil
+}
@@ -1,17 +1,17 @@
 package kubeclient
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
 	"golang.org/x/build/kubernetes/api"
 	"golang.org/x/net/context"
-	"golang.org/x/net/context/ctxhttp"
 )
 
 const (
@@ -20,51 +20,125 @@ const (
 	watchPodPath = apiPrefix + "/watch/namespaces/%s/pods/%s"
 )
 
+// CreatePod creates the pod and waits up to 5 minutes for it to leave the
+// Pending phase. If it does not, the pod is deleted and a *PodPendingError
+// explaining what held it up is returned.
 func (c *Client) CreatePod(ctx context.Context, pod *api.Pod) (*api.Pod, error) {
+	if err := c.checkPodPolicy(pod); err != nil {
+		return nil, err
+	}
+	namespace, err := c.resolveNamespace("", pod.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
 	var podJSON bytes.Buffer
 	if err := json.NewEncoder(&podJSON).Encode(pod); err != nil {
 		return nil, fmt.Errorf("failed to encode pod in json: %v", err)
 	}
 
-	apiResult, err := CreateKubeResource(ctx, &PodResource{c.Host, pod.Namespace, ""}, podJSON, c.Client)
+	apiResult, err := CreateKubeResource(ctx, &PodResource{c.Host, namespace, ""}, podJSON, c.Client)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to create pod for namespace %s. \nError: %v", pod.Namespace, err)
+		err = c.conflictError(ctx, err, c.podURL(namespace, pod.Name), pod)
+		return nil, fmt.Errorf("Failed to create pod for namespace %s. \nError: %w", namespace, err)
 	}
 
 	var podResult api.Pod
 	if err := json.Unmarshal(apiResult, &podResult); err != nil {
-		return nil, fmt.Errorf("Failed to decode pod resources for namespace %s. \nError: %v", pod.Namespace, err)
+		return nil, fmt.Errorf("Failed to decode pod resources for namespace %s. \nError: %v", namespace, err)
 	}
 
 	// Give the pod 5 minutes to leave "Pending" state
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	ctx, cancel := withTimeout(ctx, c.clock(), 5*time.Minute)
 	defer cancel()
 
-	createdPod, err := c.AwaitPodNotPending(ctx, pod.Namespace, podResult.Name, podResult.ObjectMeta.ResourceVersion)
+	createdPod, err := c.AwaitPodNotPending(ctx, namespace, podResult.Name, podResult.ObjectMeta.ResourceVersion)
 	if err != nil {
-		// The pod did not leave the pending state. We should try to manually delete it before returning an error.
-		c.DeletePod(context.Background(), pod.Namespace, podResult.Name)
-		return nil, fmt.Errorf("Pod %s for namespace %s did not leave 'pending' state after waiting 5 minutes.\n Error: %v", podResult.Name, pod.Namespace, err)
+		// The pod did not leave the pending state. Gather why, then try
+		// to manually delete it before returning an error.
+		pending := c.podPendingError(namespace, podResult.Name, err)
+		c.DeletePod(context.Background(), namespace, podResult.Name)
+		return nil, pending
 	}
 	return createdPod, nil
 }
 
+// GetPod gets the specified Kubernetes pod.
+func (c *Client) GetPod(ctx context.Context, namespace, podName string) (*api.Pod, error) {
+	apiResult, err := GetKubeResource(ctx, c.podURL(namespace, podName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var pod api.Pod
+	if err := json.Unmarshal(apiResult, &pod); err != nil {
+		return nil, fmt.Errorf("failed to decode pod json: %v", err)
+	}
+	return &pod, nil
+}
+
 // PodDelete deletes the specified Kubernetes pod.
-func (c *Client) DeletePod(ctx context.Context, namespace, podName string) error {
+func (c *Client) DeletePod(ctx context.Context, namespace, podName string, opts ...DeleteOptions) error {
 	url := c.podURL(namespace, podName)
-	return DeleteKubeResource(ctx, url, c.Client)
+	return deleteKubeResource(ctx, url, firstDeleteOptions(opts), c.Client)
 }
 
-func (c *Client) UpdatePod(ctx context.Context, namespace, podName, image, version string) error {
-	return nil
+// DeletePods deletes the pods in namespace that selector matches, in a
+// single request. The selector must not be empty.
+func (c *Client) DeletePods(ctx context.Context, namespace string, selector *Selector, opts ...DeleteOptions) error {
+	var options DeleteOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	_, err := DeleteKubeResourceCollection(ctx, WithSelector(&PodResource{c.Host, namespace, ""}, selector), options, c.Client)
+	return err
 }
 
-func (c *Client) PodList(ctx context.Context, namespace, label string) ([]api.Pod, error) {
+// UpdatePod replaces the pod. Only a pod's labels, annotations, container
+// images, activeDeadlineSeconds, and added tolerations may change; the
+// apiserver rejects other changes. Its ResourceVersion guards against
+// overwriting concurrent changes.
+func (c *Client) UpdatePod(ctx context.Context, pod *api.Pod) (*api.Pod, error) {
+	var podJSON bytes.Buffer
+	if err := json.NewEncoder(&podJSON).Encode(pod); err != nil {
+		return nil, fmt.Errorf("failed to encode pod in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", pod.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	url := c.podURL(namespace, pod.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, podJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, pod)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var podResult api.Pod
+	if err := json.Unmarshal(apiResult, &podResult); err != nil {
+		return nil, fmt.Errorf("failed to decode pod resources: %v", err)
+	}
+	return &podResult, nil
+}
+
+// PatchPod applies patch, in the format given by patchType, to the pod and
+// returns the patched pod.
+func (c *Client) PatchPod(ctx context.Context, namespace, podName string, patchType PatchType, patch []byte) (*api.Pod, error) {
+	body, err := PatchKubeResource(ctx, c.podURL(namespace, podName), patchType, patch, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Patch failed: %w", err)
+	}
+	var pod api.Pod
+	if err := json.Unmarshal(body, &pod); err != nil {
+		return nil, fmt.Errorf("failed to decode pod json: %v", err)
+	}
+	return &pod, nil
+}
+
+func (c *Client) PodList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.Pod, error) {
 	var pods []api.Pod
 
-	apiResult, err := ListKubeResources(ctx, &PodResource{c.Host, namespace, label}, c.Client)
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &PodResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
 	if err != nil {
-		return pods, fmt.Errorf("Resource List failed: %v", err)
+		return pods, fmt.Errorf("Resource List failed: %w", err)
 	}
 	var podList api.PodList
 	if err := json.Unmarshal(apiResult, &podList); err != nil {
@@ -74,26 +148,44 @@ func (c *Client) PodList(ctx context.Context, namespace, label string) ([]api.Po
 	return podList.Items, nil
 }
 
-// PodLog retrieves the container log for the first container in the pod.
-func (c *Client) PodLog(ctx context.Context, namespace, podName string) (string, error) {
-	url := c.podURL(namespace, podName) + "/log"
-	req, err := http.NewRequest("GET", url, nil)
+// podListPageSize is the number of pods PodListAll fetches at a time.
+const podListPageSize = 500
+
+// PodListPage lists a page of the pods in namespace matching label, as
+// ListKubeResourcesPage does, and returns the continue token for the next
+// page, which is empty after the last one.
+func (c *Client) PodListPage(ctx context.Context, namespace, label string, opts ListOptions) ([]api.Pod, string, error) {
+	apiResult, next, err := ListKubeResourcesPage(ctx, &PodResource{c.Host, namespace, label}, opts, c.Client)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: GET %q : %v", url, err)
+		return nil, "", fmt.Errorf("Resource List failed: %w", err)
 	}
-	res, err := ctxhttp.Do(ctx, c.Client, req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: GET %q: %v", url, err)
+	var podList api.PodList
+	if err := json.Unmarshal(apiResult, &podList); err != nil {
+		return nil, "", fmt.Errorf("failed to decode pod resources: %v", err)
 	}
-	body, err := ioutil.ReadAll(res.Body)
-	res.Body.Close()
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: GET %q: %v", url, err)
+	return podList.Items, next, nil
+}
+
+// PodListAll lists the pods in namespace matching label a page at a time,
+// so that listing a large namespace does not time out, and returns them
+// all. Pages hold opts.Limit pods, or 500 if it is not set.
+func (c *Client) PodListAll(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.Pod, error) {
+	pageOpts := firstListOptions(opts)
+	if pageOpts.Limit <= 0 {
+		pageOpts.Limit = podListPageSize
 	}
-	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("http error %d GET %q: %q: %v", res.StatusCode, url, string(body), err)
+	var pods []api.Pod
+	for {
+		page, next, err := c.PodListPage(ctx, namespace, label, pageOpts)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, page...)
+		if next == "" {
+			return pods, nil
+		}
+		pageOpts.Continue = next
 	}
-	return string(body), nil
 }
 
 type PodResource struct {
@@ -117,29 +209,15 @@ func (pod *PodResource) KubeResourceLabel() string {
 // AwaitPodNotPending will return a pod's status in a podStatusResult when the pod is no longer in the pending state.
 // The podResourceVersion is required to prevent a pod's entire history from being retrieved when the watch is initiated.
 // If there is an error polling for the pod's status, or if ctx.Done is closed, podStatusResult will contain an error.
+// The error is a *PodEventsError carrying the pod's Warning events, such as ImagePullBackOff, when they can be fetched.
+// If ctx carries a ProgressFunc (see WithPodProgress), it is called with each pod change and pod event seen meanwhile.
 func (c *Client) AwaitPodNotPending(ctx context.Context, namespace, podName, podResourceVersion string) (*api.Pod, error) {
 	if podResourceVersion == "" {
 		return nil, fmt.Errorf("resourceVersion for pod %v must be provided", podName)
 	}
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	podStatusResult, err := c.WatchPod(ctx, namespace, podName, podResourceVersion)
-	if err != nil {
-		return nil, err
-	}
-	var psr PodStatusResult
-	for {
-		select {
-		case psr = <-podStatusResult:
-			if psr.Err != nil {
-				return nil, psr.Err
-			}
-			if psr.Pod.Status.Phase != api.PodPending {
-				return psr.Pod, nil
-			}
-		}
-	}
+	return c.AwaitPodCondition(ctx, namespace, podName, podResourceVersion, func(pod *api.Pod) (bool, error) {
+		return pod.Status.Phase != api.PodPending, nil
+	})
 }
 
 // PodStatusResult wraps a api.PodStatus and error
@@ -152,8 +230,8 @@ type PodStatusResult struct {
 type watchPodStatus struct {
 	// The type of watch update contained in the message
 	Type string `json:"type"`
-	// Pod details
-	Object api.Pod `json:"object"`
+	// Pod details, or an api.Status for ERROR updates
+	Object json.RawMessage `json:"object"`
 }
 
 // WatchPod long-polls the Kubernetes watch API to be notified
@@ -162,7 +240,11 @@ type watchPodStatus struct {
 // The podResourceVersion is required to prevent a pod's entire
 // history from being retrieved when the watch is initiated.
 // The provided context must be canceled or timed out to stop the watch.
-// If any error occurs communicating with the Kubernetes API, the
+// When the apiserver closes the watch, as it does after a timeout, the
+// watch resumes from the last resourceVersion seen. If the resourceVersion
+// expires (410 Gone), the pod is fetched again and sent with Type
+// WatchResyncNeeded, and the watch resumes from there.
+// If any other error occurs communicating with the Kubernetes API, the
 // error will be sent on the returned PodStatusResult channel and
 // it will be closed.
 func (c *Client) WatchPod(ctx context.Context, namespace, podName, podResourceVersion string) (<-chan PodStatusResult, error) {
@@ -173,55 +255,98 @@ func (c *Client) WatchPod(ctx context.Context, namespace, podName, podResourceVe
 
 	go func() {
 		defer close(statusChan)
-		// Make request to Kubernetes API
-		watchPodUrl := fmt.Sprintf(watchPodPath, namespace, podName)
-		getURL := c.Host + watchPodUrl
-		req, err := http.NewRequest("GET", getURL, nil)
-		req.URL.Query().Add("resourceVersion", podResourceVersion)
-		if err != nil {
-			statusChan <- PodStatusResult{Err: fmt.Errorf("failed to create request: GET %q : %v", getURL, err)}
-			return
-		}
-		res, err := ctxhttp.Do(ctx, c.Client, req)
-		defer res.Body.Close()
-		if err != nil {
-			statusChan <- PodStatusResult{Err: fmt.Errorf("failed to make request: GET %q: %v", getURL, err)}
-			return
-		}
-
-		var wps watchPodStatus
-		reader := bufio.NewReader(res.Body)
-
-		// bufio.Reader.ReadBytes is blocking, so we watch for
-		// context timeout or cancellation in a goroutine
-		// and close the response body when see see it. The
-		// response body is also closed via defer when the
-		// request is made, but closing twice is OK.
-		go func() {
-			<-ctx.Done()
-			res.Body.Close()
-		}()
-
+		resourceVersion := podResourceVersion
+		backoff := watchBackoff{clock: c.clock()}
 		for {
-			line, err := reader.ReadBytes('\n')
-			if ctx.Err() != nil {
-				statusChan <- PodStatusResult{Err: ctx.Err()}
+			err := c.watchPod(ctx, namespace, podName, &resourceVersion, statusChan, &backoff)
+			if err == ErrWatchClosed {
+				continue
+			}
+			if err != ErrWatchGone {
+				statusChan <- PodStatusResult{Err: err}
 				return
 			}
-			if err != nil {
-				statusChan <- PodStatusResult{Err: fmt.Errorf("error reading streaming response body: %v", err)}
+			if err := backoff.wait(ctx); err != nil {
+				statusChan <- PodStatusResult{Err: err}
 				return
 			}
-			if err := json.Unmarshal(line, &wps); err != nil {
-				statusChan <- PodStatusResult{Err: fmt.Errorf("failed to decode watch pod status: %v", err)}
+			pod, err := c.GetPod(ctx, namespace, podName)
+			if err != nil {
+				statusChan <- PodStatusResult{Err: fmt.Errorf("failed to relist pod after 410 Gone: %w", err)}
 				return
 			}
-			statusChan <- PodStatusResult{Pod: &wps.Object, Type: wps.Type}
+			statusChan <- PodStatusResult{Pod: pod, Type: WatchResyncNeeded}
+			resourceVersion = pod.ResourceVersion
 		}
 	}()
 	return statusChan, nil
 }
 
+// watchPod runs a single watch connection for the pod, sending its events on
+// statusChan and advancing resourceVersion past them, until the connection
+// fails. It returns ErrWatchGone if resourceVersion has expired and
+// ErrWatchClosed if the apiserver ended the watch.
+func (c *Client) watchPod(ctx context.Context, namespace, podName string, resourceVersion *string, statusChan chan<- PodStatusResult, backoff *watchBackoff) error {
+	// Make request to Kubernetes API
+	values := url.Values{}
+	values.Set("resourceVersion", *resourceVersion)
+	getURL := c.Host + fmt.Sprintf(watchPodPath, namespace, podName) + "?" + values.Encode()
+	req, err := http.NewRequest("GET", getURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: GET %q : %v", getURL, err)
+	}
+	res, err := doRequest(ctx, c.Client, req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: GET %q: %v", getURL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusGone {
+		return ErrWatchGone
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return newStatusError("GET", getURL, res.StatusCode, body)
+	}
+
+	// Decoding blocks on the response body, so we watch for
+	// context timeout or cancellation in a goroutine
+	// and close the response body when see see it. The
+	// response body is also closed via defer when the
+	// request is made, but closing twice is OK.
+	stop := closeOnDone(ctx, res.Body)
+	defer stop()
+
+	// The watch stream is a sequence of JSON objects. Decoding them
+	// directly copes with events of any size and with objects split
+	// across chunks, unlike reading newline-delimited lines.
+	decoder := json.NewDecoder(res.Body)
+	for {
+		var wps watchPodStatus
+		err := decoder.Decode(&wps)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == io.EOF {
+			return ErrWatchClosed
+		}
+		if err != nil {
+			return fmt.Errorf("error reading streaming response body: %v", err)
+		}
+		if wps.Type == "ERROR" {
+			return watchStatusError(wps.Object)
+		}
+		var pod api.Pod
+		if err := json.Unmarshal(wps.Object, &pod); err != nil {
+			return fmt.Errorf("failed to decode watch pod status: %v", err)
+		}
+		backoff.reset()
+		if pod.ResourceVersion != "" {
+			*resourceVersion = pod.ResourceVersion
+		}
+		statusChan <- PodStatusResult{Pod: &pod, Type: wps.Type}
+	}
+}
+
 func (c *Client) podURL(namespace, name string) string {
 	return c.Host + fmt.Sprintf(podPath, namespace, name)
 }
@@ -0,0 +1,91 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// WatchPods watches many pods over a single connection: it watches the
+// pods in namespace matching label and filters the events by name on the
+// client, sending only those about the named pods. It is meant for large
+// sets of pods, such as thousands of batch pods sharing a label, which
+// would exhaust the apiserver's connection limits with one WatchPod each.
+// If names is empty, every matching pod is included.
+//
+// The named pods that already exist are sent first. The provided context
+// must be canceled or timed out to stop the watch. If the watch expires
+// (410 Gone) or the apiserver closes it, the pods are listed again and each
+// is sent with Type WatchResyncNeeded before the watch resumes; pods
+// deleted meanwhile are not reported. If any other error occurs, it is
+// sent on the returned channel and the channel is closed.
+func (c *Client) WatchPods(ctx context.Context, namespace, label string, names []string) (<-chan PodStatusResult, error) {
+	if label == "" && len(names) > 0 {
+		return nil, fmt.Errorf("a label selector is required to watch %d named pods", len(names))
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	include := func(pod *api.Pod) bool {
+		return len(wanted) == 0 || wanted[pod.Name]
+	}
+	statusChan := make(chan PodStatusResult)
+	send := func(result PodStatusResult) bool {
+		select {
+		case statusChan <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(statusChan)
+		kubeResource := &PodResource{c.Host, namespace, label}
+		eventType := "ADDED"
+		backoff := watchBackoff{clock: c.clock()}
+		for {
+			apiResult, err := ListKubeResources(ctx, kubeResource, c.Client)
+			if err != nil {
+				send(PodStatusResult{Err: fmt.Errorf("Resource List failed: %w", err)})
+				return
+			}
+			var podList api.PodList
+			if err := json.Unmarshal(apiResult, &podList); err != nil {
+				send(PodStatusResult{Err: fmt.Errorf("failed to decode pod resources: %v", err)})
+				return
+			}
+			for i := range podList.Items {
+				pod := &podList.Items[i]
+				if include(pod) && !send(PodStatusResult{Pod: pod, Type: eventType}) {
+					return
+				}
+			}
+
+			err = watchKubeResources(ctx, kubeResource, podList.ResourceVersion, c.Client, func(eventType string, object json.RawMessage) error {
+				var pod api.Pod
+				if err := json.Unmarshal(object, &pod); err != nil {
+					return fmt.Errorf("failed to decode watch pod status: %v", err)
+				}
+				backoff.reset()
+				if include(&pod) && !send(PodStatusResult{Pod: &pod, Type: eventType}) {
+					return ctx.Err()
+				}
+				return nil
+			})
+			if err != ErrWatchGone && err != ErrWatchClosed {
+				send(PodStatusResult{Err: err})
+				return
+			}
+			if err := backoff.wait(ctx); err != nil {
+				send(PodStatusResult{Err: err})
+				return
+			}
+			eventType = WatchResyncNeeded
+		}
+	}()
+	return statusChan, nil
+}
@@ -0,0 +1,159 @@
+package kubeclient
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// ErrPodDeleted is returned by AwaitPodCondition when the pod is deleted
+// before the condition holds.
+var ErrPodDeleted = errors.New("pod was deleted")
+
+// AwaitPodCondition watches the pod for changes after resourceVersion until
+// cond reports that it holds, or returns an error, and returns the pod as
+// cond last saw it. If resourceVersion is empty, the pod is fetched and
+// checked first, and watched from there. If the pod is deleted first,
+// ErrPodDeleted is returned. A watch that the apiserver closes or whose
+// connection fails is resumed from the last pod seen, so pods that take
+// longer than the apiserver's watch timeout are waited for too. Other
+// errors watching the pod, including ctx being done, are returned as a
+// *PodEventsError carrying the pod's Warning events when they can be
+// fetched. If ctx carries a ProgressFunc (see WithPodProgress), it is
+// called with each pod change and pod event seen meanwhile.
+func (c *Client) AwaitPodCondition(ctx context.Context, namespace, podName, resourceVersion string, cond func(*api.Pod) (bool, error)) (*api.Pod, error) {
+	if resourceVersion == "" {
+		pod, err := c.GetPod(ctx, namespace, podName)
+		if err != nil {
+			return nil, err
+		}
+		if done, err := cond(pod); done || err != nil {
+			return pod, err
+		}
+		resourceVersion = pod.ResourceVersion
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	progress := podProgressFromContext(ctx)
+	var events <-chan EventResult
+	if progress != nil {
+		events = c.watchPodEvents(ctx, namespace, podName)
+	}
+	backoff := watchBackoff{clock: c.clock()}
+	for {
+		pod, err, watchErr := c.awaitPodWatch(ctx, namespace, podName, &resourceVersion, cond, progress, &events, &backoff)
+		if watchErr == nil {
+			return pod, err
+		}
+		if ctx.Err() != nil || statusCode(watchErr) != 0 {
+			return nil, c.withPodEvents(namespace, podName, watchErr)
+		}
+		// A connection failure; watch again from the last pod seen.
+		if err := backoff.wait(ctx); err != nil {
+			return nil, c.withPodEvents(namespace, podName, err)
+		}
+	}
+}
+
+// awaitPodWatch runs a single WatchPod for AwaitPodCondition, advancing
+// resourceVersion past the pods it sees. The error that ends the watch is
+// returned as watchErr, apart from the outcome of the wait.
+func (c *Client) awaitPodWatch(ctx context.Context, namespace, podName string, resourceVersion *string, cond func(*api.Pod) (bool, error), progress ProgressFunc, events *<-chan EventResult, backoff *watchBackoff) (pod *api.Pod, err, watchErr error) {
+	ctx, cancel := context.WithCancel(ctx)
+	podStatusResult, err := c.WatchPod(ctx, namespace, podName, *resourceVersion)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	defer func() {
+		cancel()
+		// Let the watch deliver its last result and close.
+		go func() {
+			for range podStatusResult {
+			}
+		}()
+	}()
+
+	for {
+		select {
+		case psr := <-podStatusResult:
+			if psr.Err != nil {
+				return nil, nil, psr.Err
+			}
+			backoff.reset()
+			*resourceVersion = psr.Pod.ResourceVersion
+			if progress != nil {
+				progress(PodProgress{Pod: psr.Pod})
+			}
+			if psr.Type == "DELETED" {
+				return psr.Pod, ErrPodDeleted, nil
+			}
+			if done, err := cond(psr.Pod); done || err != nil {
+				return psr.Pod, err, nil
+			}
+		case er, ok := <-*events:
+			switch {
+			case !ok || er.Err != nil:
+				// Stop listening; the pod watch carries on alone.
+				*events = nil
+			case er.Event != nil:
+				progress(PodProgress{Event: er.Event})
+			}
+		}
+	}
+}
+
+// podFinished returns an error if the pod has finished, for conditions
+// that can no longer come to hold once it has.
+func podFinished(pod *api.Pod) error {
+	switch pod.Status.Phase {
+	case api.PodSucceeded, api.PodFailed:
+		return fmt.Errorf("pod %s finished with phase %s", pod.Name, pod.Status.Phase)
+	}
+	return nil
+}
+
+// AwaitPodRunning waits for the pod to reach the Running phase. It fails if
+// the pod finishes without being seen running.
+func (c *Client) AwaitPodRunning(ctx context.Context, namespace, podName string) (*api.Pod, error) {
+	return c.AwaitPodCondition(ctx, namespace, podName, "", func(pod *api.Pod) (bool, error) {
+		return pod.Status.Phase == api.PodRunning, podFinished(pod)
+	})
+}
+
+// AwaitPodReady waits for the pod's Ready condition to be True. It fails if
+// the pod finishes first.
+func (c *Client) AwaitPodReady(ctx context.Context, namespace, podName string) (*api.Pod, error) {
+	return c.AwaitPodCondition(ctx, namespace, podName, "", func(pod *api.Pod) (bool, error) {
+		if cond := GetPodCondition(pod, api.PodReady); cond != nil && cond.Status == api.ConditionTrue {
+			return true, nil
+		}
+		return false, podFinished(pod)
+	})
+}
+
+// AwaitPodSucceeded waits for the pod to reach the Succeeded phase. It
+// fails if the pod fails instead.
+func (c *Client) AwaitPodSucceeded(ctx context.Context, namespace, podName string) (*api.Pod, error) {
+	return c.AwaitPodCondition(ctx, namespace, podName, "", func(pod *api.Pod) (bool, error) {
+		if pod.Status.Phase == api.PodFailed {
+			return false, fmt.Errorf("pod %s failed: %s: %s", pod.Name, pod.Status.Reason, pod.Status.Message)
+		}
+		return pod.Status.Phase == api.PodSucceeded, nil
+	})
+}
+
+// AwaitPodDeleted waits for the pod to be deleted, returning straight away
+// if it does not exist.
+func (c *Client) AwaitPodDeleted(ctx context.Context, namespace, podName string) error {
+	_, err := c.AwaitPodCondition(ctx, namespace, podName, "", func(*api.Pod) (bool, error) {
+		return false, nil
+	})
+	if err == ErrPodDeleted || IsNotFound(err) {
+		return nil
+	}
+	return err
+}
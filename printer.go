@@ -0,0 +1,134 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"golang.org/x/build/kubernetes/api"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	redacted              = "<redacted>"
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// Sprint renders obj, any value that encodes to a Kubernetes JSON object, as
+// YAML for debug output. metadata.managedFields is stripped, and the data of
+// Secrets, including secrets inside a list, is redacted.
+func Sprint(obj interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := Fprint(&b, obj); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Fprint writes obj to w the same way Sprint renders it.
+func Fprint(w io.Writer, obj interface{}) error {
+	return fprint(w, obj, StripOptions{ManagedFields: true})
+}
+
+// fprint is Fprint with the fields stripped chosen by strip.
+func fprint(w io.Writer, obj interface{}, strip StripOptions) error {
+	objJSON, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to encode object in json: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(objJSON))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("failed to decode object json: %v", err)
+	}
+
+	var secret bool
+	switch obj.(type) {
+	case *api.Secret, api.Secret, *api.SecretList, api.SecretList:
+		secret = true
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		strip.strip(m)
+		sanitize(m, secret)
+	}
+
+	out, err := yaml.Marshal(yamlValue(v))
+	if err != nil {
+		return fmt.Errorf("failed to encode object in yaml: %v", err)
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// sanitize redacts the data of obj if it is a Secret. Items of list objects
+// are sanitized as well.
+func sanitize(obj map[string]interface{}, secret bool) {
+	kind, _ := obj["kind"].(string)
+	if items, ok := obj["items"].([]interface{}); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]interface{}); ok {
+				sanitize(m, secret || kind == "SecretList")
+			}
+		}
+	}
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if !secret && kind != "Secret" {
+		return
+	}
+	for _, field := range []string{"data", "stringData"} {
+		if data, ok := obj[field].(map[string]interface{}); ok {
+			for k := range data {
+				data[k] = redacted
+			}
+		}
+	}
+	// The last applied configuration holds a full copy of the data.
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		if _, ok := annotations[lastAppliedAnnotation]; ok {
+			annotations[lastAppliedAnnotation] = redacted
+		}
+	}
+}
+
+// yamlValue converts v, decoded from JSON with UseNumber, to the value
+// yaml.Marshal should encode: objects become MapSlices with their keys
+// sorted, so the output is stable, and numbers become ints or floats
+// rather than strings.
+func yamlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		m := make(yaml.MapSlice, len(keys))
+		for i, k := range keys {
+			m[i] = yaml.MapItem{Key: k, Value: yamlValue(v[k])}
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, item := range v {
+			l[i] = yamlValue(item)
+		}
+		return l
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return n
+		}
+		if n, err := v.Float64(); err == nil {
+			return n
+		}
+		return v.String()
+	}
+	return v
+}
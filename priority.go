@@ -0,0 +1,94 @@
+package kubeclient
+
+import (
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+// Priority hints how urgently a request should be sent when the client's
+// rate limiter is holding requests back.
+type Priority int
+
+const (
+	// PriorityLow is for bulk background work such as periodic full lists.
+	PriorityLow Priority = iota - 1
+	// PriorityNormal is the priority of requests that were not tagged.
+	PriorityNormal
+	// PriorityHigh is for interactive operations a user is waiting on.
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	}
+	return "unknown"
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx that tags every request made with it
+// with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority ctx was tagged with, or
+// PriorityNormal if it was not tagged.
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// RateLimiter throttles outgoing requests. Wait blocks until a request of
+// the given priority may be sent, and must let higher priority requests go
+// ahead of lower priority ones that are still waiting. It returns an error
+// if ctx is done first.
+type RateLimiter interface {
+	Wait(ctx context.Context, p Priority) error
+}
+
+// UseRateLimiter makes every request made through c wait on rl first, with
+// the priority its context was tagged with. Each retry the client's
+// RetryPolicy makes waits on rl too, so retries count against it.
+func (c *Client) UseRateLimiter(rl RateLimiter) {
+	// Limit below the retries, so every attempt takes a token.
+	rt := c.Client.Transport
+	for {
+		if t, ok := rt.(*retryTransport); ok {
+			t.rt = &limiterTransport{rl: rl, rt: t.rt}
+			return
+		}
+		w, ok := rt.(wrappedTransport)
+		if !ok {
+			break
+		}
+		rt = w.unwrap()
+	}
+	c.Client.Transport = &limiterTransport{rl: rl, rt: c.Client.Transport}
+}
+
+type limiterTransport struct {
+	rl RateLimiter
+	rt http.RoundTripper
+}
+
+func (t *limiterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := t.rl.Wait(ctx, PriorityFromContext(ctx)); err != nil {
+		return nil, err
+	}
+	return t.rt.RoundTrip(req)
+}
+
+func (t *limiterTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
@@ -0,0 +1,50 @@
+package kubeclient
+
+import (
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// PodProgress is an update about a pod that is being awaited.
+type PodProgress struct {
+	// Pod is set when the pod changed, e.g. its phase or conditions.
+	Pod *api.Pod
+	// Event is set when an event was recorded about the pod, e.g.
+	// Scheduled, Pulling, or FailedScheduling.
+	Event *api.Event
+}
+
+// ProgressFunc receives the updates seen while awaiting a pod. It is called
+// from the awaiting goroutine, so it should return quickly.
+type ProgressFunc func(PodProgress)
+
+type progressKey struct{}
+
+// WithPodProgress returns a copy of ctx that makes AwaitPodNotPending, and
+// CreatePod which uses it, call fn with every pod change and event seen
+// while waiting, so that callers can show progress rather than block
+// silently.
+func WithPodProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func podProgressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// watchPodEvents returns the events recorded about the pod, or nil if they
+// cannot be watched. Events only add detail to progress reports, so their
+// failures are ignored. The channel is drained once ctx is done.
+func (c *Client) watchPodEvents(ctx context.Context, namespace, podName string) <-chan EventResult {
+	events, err := c.WatchEvents(ctx, namespace, "involvedObject.kind=Pod,involvedObject.name="+podName, SeverityNormal)
+	if err != nil {
+		return nil
+	}
+	go func() {
+		<-ctx.Done()
+		for range events {
+		}
+	}()
+	return events
+}
@@ -0,0 +1,63 @@
+package kubeclient
+
+import (
+	"math/big"
+	"strings"
+)
+
+// quantitySuffixes maps the suffixes of resource quantities to their
+// multipliers.
+var quantitySuffixes = map[string]*big.Rat{
+	"n":  big.NewRat(1, 1000000000),
+	"u":  big.NewRat(1, 1000000),
+	"m":  big.NewRat(1, 1000),
+	"":   big.NewRat(1, 1),
+	"k":  big.NewRat(1000, 1),
+	"M":  big.NewRat(1000000, 1),
+	"G":  big.NewRat(1000000000, 1),
+	"T":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(12), nil)),
+	"P":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(15), nil)),
+	"E":  new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)),
+	"Ki": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 10)),
+	"Mi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 20)),
+	"Gi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 30)),
+	"Ti": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 40)),
+	"Pi": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 50)),
+	"Ei": new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 60)),
+}
+
+// parseQuantity parses a resource quantity such as "500m", "1.5Gi", or
+// "1e3".
+func parseQuantity(s string) (*big.Rat, bool) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '+' && r != '-'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	number, suffix := s[:i], s[i:]
+	if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') && strings.IndexAny(suffix[1:2], "0123456789+-") == 0 {
+		// Exponent notation; big.Rat parses it directly.
+		number, suffix = s, ""
+	}
+	multiplier, ok := quantitySuffixes[suffix]
+	if !ok || number == "" {
+		return nil, false
+	}
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return nil, false
+	}
+	return value.Mul(value, multiplier), true
+}
+
+// equalQuantities reports whether a and b are resource quantities of the
+// same value, such as "1" and "1000m".
+func equalQuantities(a, b string) bool {
+	x, ok := parseQuantity(a)
+	if !ok {
+		return false
+	}
+	y, ok := parseQuantity(b)
+	return ok && x.Cmp(y) == 0
+}
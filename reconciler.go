@@ -0,0 +1,245 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// ReconcileFunc brings the object identified by key ("namespace/name", or
+// "name" for cluster-scoped resources) to its desired state. It is also
+// called after the object is deleted, so it must cope with it being gone.
+// Returning an error requeues the key with exponential backoff.
+type ReconcileFunc func(ctx context.Context, key string) (Result, error)
+
+// Result tells a Controller whether to process a key again.
+type Result struct {
+	// Requeue processes the key again after an exponential backoff.
+	Requeue bool
+	// RequeueAfter processes the key again after the given duration.
+	RequeueAfter time.Duration
+}
+
+// Controller calls Reconcile for every object of a resource, and again each
+// time the object changes. It lists the resource, then watches it from the
+// listed resourceVersion, re-listing when the watch fails or expires.
+// Keys are queued so that an object is only reconciled by one worker at a
+// time, however often it changes.
+type Controller struct {
+	Client *Client
+	// Resource selects the objects to reconcile; its label selector is
+	// honored.
+	Resource  KubeResource
+	Reconcile ReconcileFunc
+	// Workers is the number of keys reconciled concurrently. It defaults
+	// to 1.
+	Workers int
+	// ResyncInterval, if set, re-lists and reconciles every object
+	// periodically to repair missed events.
+	ResyncInterval time.Duration
+	// OnError, if set, is called with list and watch failures, which are
+	// otherwise retried silently.
+	OnError func(error)
+}
+
+// listedObject holds what a Controller needs from each listed object.
+type listedObject struct {
+	Metadata struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"metadata"`
+}
+
+func (o *listedObject) key() string {
+	if o.Metadata.Namespace == "" {
+		return o.Metadata.Name
+	}
+	return o.Metadata.Namespace + "/" + o.Metadata.Name
+}
+
+// SplitKey splits a Controller key into its namespace and name.
+func SplitKey(key string) (namespace, name string) {
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// Run reconciles until ctx is done. It then stops watching, waits for the
+// reconciles in progress to return, and returns ctx.Err().
+func (ctrl *Controller) Run(ctx context.Context) error {
+	workers := ctrl.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	queue := newWorkQueue(ctrl.Client.clock())
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctrl.work(ctx, queue)
+		}()
+	}
+	go ctrl.listWatch(ctx, queue)
+	if ctrl.ResyncInterval > 0 {
+		go resync(ctx, ctrl.Client.clock(), ctrl.ResyncInterval, func(ctx context.Context) {
+			if _, err := ctrl.list(ctx, queue); err != nil {
+				ctrl.error(err)
+			}
+		})
+	}
+
+	<-ctx.Done()
+	queue.shutDown()
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (ctrl *Controller) work(ctx context.Context, queue *workQueue) {
+	for {
+		key, ok := queue.get()
+		if !ok {
+			return
+		}
+		result, err := ctrl.Reconcile(ctx, key)
+		switch {
+		case err != nil || result.Requeue:
+			queue.addRateLimited(key)
+		case result.RequeueAfter > 0:
+			queue.forget(key)
+			queue.addAfter(key, result.RequeueAfter)
+		default:
+			queue.forget(key)
+		}
+		queue.done(key)
+	}
+}
+
+// listWatch keeps the queue fed until ctx is done.
+func (ctrl *Controller) listWatch(ctx context.Context, queue *workQueue) {
+	backoff := watchBackoff{clock: ctrl.Client.clock()}
+	for ctx.Err() == nil {
+		resourceVersion, err := ctrl.list(ctx, queue)
+		if err == nil {
+			err = watchKubeResources(ctx, ctrl.Resource, resourceVersion, ctrl.Client.Client, func(eventType string, object json.RawMessage) error {
+				var obj listedObject
+				if err := json.Unmarshal(object, &obj); err != nil {
+					return fmt.Errorf("failed to decode watched object: %v", err)
+				}
+				backoff.reset()
+				queue.add(obj.key())
+				return nil
+			})
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != ErrWatchGone && err != ErrWatchClosed {
+			ctrl.error(err)
+		}
+		if backoff.wait(ctx) != nil {
+			return
+		}
+	}
+}
+
+// list queues every object of the resource and returns the list's
+// resourceVersion.
+func (ctrl *Controller) list(ctx context.Context, queue *workQueue) (string, error) {
+	apiResult, err := ListKubeResources(ctx, ctrl.Resource, ctrl.Client.Client)
+	if err != nil {
+		return "", fmt.Errorf("Resource List failed: %w", err)
+	}
+	var list struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+		Items []listedObject `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &list); err != nil {
+		return "", fmt.Errorf("failed to decode resources: %v", err)
+	}
+	for i := range list.Items {
+		queue.add(list.Items[i].key())
+	}
+	return list.Metadata.ResourceVersion, nil
+}
+
+func (ctrl *Controller) error(err error) {
+	if ctrl.OnError != nil {
+		ctrl.OnError(err)
+	}
+}
+
+// UpdateStatus replaces the status of the named object of kubeResource via
+// its status subresource and returns the updated object. obj must carry the
+// object's resourceVersion.
+func (c *Client) UpdateStatus(ctx context.Context, kubeResource KubeResource, name string, obj interface{}) ([]byte, error) {
+	var objJSON bytes.Buffer
+	if err := json.NewEncoder(&objJSON).Encode(obj); err != nil {
+		return nil, fmt.Errorf("failed to encode object in json: %v", err)
+	}
+	url := kubeResource.KubeResourcesURL() + "/" + name + "/status"
+	return UpdateKubeResource(ctx, url, objJSON, c.Client)
+}
+
+// ControllerReference returns the owner reference a controller sets on the
+// objects it creates, so they are garbage collected with it.
+func ControllerReference(apiVersion, kind, name, uid string) OwnerReference {
+	t := true
+	return OwnerReference{
+		APIVersion:         apiVersion,
+		Kind:               kind,
+		Name:               name,
+		UID:                uid,
+		Controller:         &t,
+		BlockOwnerDeletion: &t,
+	}
+}
+
+// DefaultConflictRetryPolicy returns the policy RetryOnConflict uses when
+// given none: up to 4 retries, waiting from 10ms up to 1s between them.
+func DefaultConflictRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:     4,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+}
+
+// RetryOnConflict calls fn, and calls it again, waiting as backoff
+// directs, for as long as it fails with an error IsConflict matches and
+// retries remain. fn should fetch the object afresh, apply its change, and
+// write it back guarded by the fetched resourceVersion. A nil backoff uses
+// DefaultConflictRetryPolicy. It returns fn's last error, or ctx's if ctx
+// is done while waiting.
+func RetryOnConflict(ctx context.Context, backoff *RetryPolicy, fn func() error) error {
+	return retryOnConflict(ctx, realClock{}, backoff, fn)
+}
+
+// retryOnConflict is RetryOnConflict waiting on clock.
+func retryOnConflict(ctx context.Context, clock Clock, backoff *RetryPolicy, fn func() error) error {
+	if backoff == nil {
+		backoff = DefaultConflictRetryPolicy()
+	}
+	for retry := 1; ; retry++ {
+		err := fn()
+		if err == nil || !IsConflict(err) || retry > backoff.MaxRetries {
+			return err
+		}
+		timer := clock.NewTimer(backoff.backoff(retry))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package kubeclient
+
+import (
+	"fmt"
+	"net/url"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// ResourceRef addresses a single object of any resource, for the methods
+// that work on objects generically, such as GetRef and DeleteRef, and for
+// tooling that records or compares objects of many kinds. For example, a
+// deployment is ResourceRef{"apps", "v1", "deployments", namespace, name}.
+type ResourceRef struct {
+	// Group is the API group, "" for the core group.
+	Group   string
+	Version string
+	// Resource is the plural, lower case name of the resource.
+	Resource string
+	// Namespace is empty for cluster-scoped resources.
+	Namespace string
+	Name      string
+}
+
+// String returns the ref as "<group>/<version>/<resource>/<namespace>/<name>",
+// leaving out the group of core resources and the namespace of
+// cluster-scoped ones, e.g. "v1/pods/default/web-1" or
+// "apps/v1/deployments/default/web".
+func (r ResourceRef) String() string {
+	s := r.Version + "/" + r.Resource
+	if r.Group != "" {
+		s = r.Group + "/" + s
+	}
+	if r.Namespace != "" {
+		s += "/" + r.Namespace
+	}
+	return s + "/" + r.Name
+}
+
+// Collection returns the KubeResource of the collection the object is in.
+func (r ResourceRef) Collection(host string) *APIResource {
+	return &APIResource{host, r.Group, r.Version, r.Resource, r.Namespace, ""}
+}
+
+// URL returns the object's URL on host.
+func (r ResourceRef) URL(host string) string {
+	return r.Collection(host).ObjectURL(r.Name)
+}
+
+// RefOf returns the ref of obj, which may be any of the objects the client
+// has typed methods for.
+func RefOf(obj interface{}) (ResourceRef, error) {
+	switch o := obj.(type) {
+	case *api.Pod:
+		return ResourceRef{"", "v1", "pods", o.Namespace, o.Name}, nil
+	case *api.ReplicationController:
+		return ResourceRef{"", "v1", "replicationcontrollers", o.Namespace, o.Name}, nil
+	case *api.Secret:
+		return ResourceRef{"", "v1", "secrets", o.Namespace, o.Name}, nil
+	case *api.Service:
+		return ResourceRef{"", "v1", "services", o.Namespace, o.Name}, nil
+	case *api.ServiceAccount:
+		return ResourceRef{"", "v1", "serviceaccounts", o.Namespace, o.Name}, nil
+	case *api.Node:
+		return ResourceRef{"", "v1", "nodes", "", o.Name}, nil
+	case *ConfigMap:
+		return ResourceRef{"", "v1", "configmaps", o.Namespace, o.Name}, nil
+	case *PersistentVolumeClaim:
+		return ResourceRef{"", "v1", "persistentvolumeclaims", o.Namespace, o.Name}, nil
+	case *PersistentVolume:
+		return ResourceRef{"", "v1", "persistentvolumes", "", o.Name}, nil
+	case *Deployment:
+		return ResourceRef{"apps", "v1", "deployments", o.Namespace, o.Name}, nil
+	case *StatefulSet:
+		return ResourceRef{"apps", "v1", "statefulsets", o.Namespace, o.Name}, nil
+	case *Job:
+		return ResourceRef{"batch", "v1", "jobs", o.Namespace, o.Name}, nil
+	case *APIService:
+		return ResourceRef{apiregistrationGroup, "v1", "apiservices", "", o.Name}, nil
+	case *Role:
+		return ResourceRef{rbacGroup, "v1", "roles", o.Namespace, o.Name}, nil
+	case *RoleBinding:
+		return ResourceRef{rbacGroup, "v1", "rolebindings", o.Namespace, o.Name}, nil
+	case *ClusterRole:
+		return ResourceRef{rbacGroup, "v1", "clusterroles", "", o.Name}, nil
+	case *ClusterRoleBinding:
+		return ResourceRef{rbacGroup, "v1", "clusterrolebindings", "", o.Name}, nil
+	}
+	return ResourceRef{}, fmt.Errorf("unsupported object type %T", obj)
+}
+
+// GetRef gets the object ref addresses, as raw JSON.
+func (c *Client) GetRef(ctx context.Context, ref ResourceRef) ([]byte, error) {
+	apiResult, err := GetKubeResource(ctx, ref.URL(c.Host), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	return apiResult, nil
+}
+
+// DeleteRef deletes the object ref addresses.
+func (c *Client) DeleteRef(ctx context.Context, ref ResourceRef, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, ref.URL(c.Host), firstDeleteOptions(opts), c.Client)
+}
+
+// PatchRef applies body, a patch in the format given by patchType, to the
+// object ref addresses and returns the patched object as raw JSON.
+func (c *Client) PatchRef(ctx context.Context, ref ResourceRef, patchType PatchType, body []byte) ([]byte, error) {
+	apiResult, err := PatchKubeResource(ctx, ref.URL(c.Host), patchType, body, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Patch failed: %w", err)
+	}
+	return apiResult, nil
+}
+
+// WatchRef is Watch restricted to the object ref addresses.
+func (c *Client) WatchRef(ctx context.Context, ref ResourceRef, resourceVersion string) (<-chan WatchEvent, error) {
+	values := url.Values{}
+	values.Set("fieldSelector", "metadata.name="+ref.Name)
+	return c.watch(ctx, ref.Collection(c.Host), values, resourceVersion)
+}
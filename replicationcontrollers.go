@@ -4,13 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
+	"strconv"
 
 	"golang.org/x/build/kubernetes/api"
 	"golang.org/x/net/context"
-	"golang.org/x/net/context/ctxhttp"
 )
 
 const (
@@ -26,9 +23,14 @@ func (c *Client) CreateReplicationController(ctx context.Context,
 		return nil, fmt.Errorf("failed to encode rc in json: %v", err)
 	}
 
-	apiResult, err := CreateKubeResource(ctx, &ReplicationControllerResource{c.Host, rc.Namespace, ""}, rcJSON, c.Client)
+	namespace, err := c.resolveNamespace("", rc.Namespace)
 	if err != nil {
-		return nil, fmt.Errorf("Create failed: %v", err)
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &ReplicationControllerResource{c.Host, namespace, ""}, rcJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.replicationControllerURL(namespace, rc.Name), rc)
+		return nil, fmt.Errorf("Create failed: %w", err)
 	}
 
 	var rcResult api.ReplicationController
@@ -39,53 +41,71 @@ func (c *Client) CreateReplicationController(ctx context.Context,
 	return &rcResult, nil
 }
 
-func (c *Client) DeleteReplicationController(ctx context.Context, namespace, replicationControllerName string) error {
+func (c *Client) GetReplicationController(ctx context.Context, namespace, name string) (*api.ReplicationController, error) {
+	apiResult, err := GetKubeResource(ctx, c.replicationControllerURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var rc api.ReplicationController
+	if err := json.Unmarshal(apiResult, &rc); err != nil {
+		return nil, fmt.Errorf("failed to decode rc json: %v", err)
+	}
+	return &rc, nil
+}
+
+func (c *Client) DeleteReplicationController(ctx context.Context, namespace, replicationControllerName string, opts ...DeleteOptions) error {
 	url := c.replicationControllerURL(namespace, replicationControllerName)
-	return DeleteKubeResource(ctx, url, c.Client)
+	return deleteKubeResource(ctx, url, firstDeleteOptions(opts), c.Client)
 }
 
-func (c *Client) UpdateReplicationControllerImage(ctx context.Context, namespace, name, image, version string) error {
-	replicationControllerURL, err := url.Parse(c.replicationControllerURL(namespace, name))
+// UpdateReplicationControllerImage sets the image of the replication
+// controller's pod template container named containerName to
+// image:version. An empty containerName picks the first container. The
+// patch checks the container is still at the index it was found at, so a
+// concurrent change to the containers fails it rather than updating the
+// wrong one.
+func (c *Client) UpdateReplicationControllerImage(ctx context.Context, namespace, name, containerName, image, version string) error {
+	rc, err := c.GetReplicationController(ctx, namespace, name)
 	if err != nil {
 		return err
 	}
-	values := url.Values{}
-	replicationControllerURL.RawQuery = values.Encode()
-	url := replicationControllerURL.String()
-	// TODO: Add support for container name lookup
-	body := []byte(fmt.Sprintf(`[{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value":"%s:%s"}]`, image, version))
-
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(body))
-	if err != nil {
-		return fmt.Errorf("failed to create request: PATCH %q : %v", url, err)
-	}
-	req.Header.Set("Content-Type", "application/json-patch+json")
-	res, err := ctxhttp.Do(ctx, c.Client, req)
-	if err != nil {
-		return fmt.Errorf("failed to make request: PATCH %q: %v", url, err)
+	if rc.Spec.Template == nil {
+		return fmt.Errorf("replication controller %s has no pod template", name)
 	}
-	body, err = ioutil.ReadAll(res.Body)
-	res.Body.Close()
-	if err != nil {
-		return fmt.Errorf("failed to read response body: PATCH %q: %v", url, err)
+	index := -1
+	for i, container := range rc.Spec.Template.Spec.Containers {
+		if containerName == "" || container.Name == containerName {
+			index = i
+			containerName = container.Name
+			break
+		}
 	}
-	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("http error %d PATCH %q: %q: %v", res.StatusCode, url, string(body), err)
+	if index < 0 {
+		return fmt.Errorf("replication controller %s has no container %q", name, containerName)
 	}
-	var replicationControllerList api.ReplicationControllerList
-	if err := json.Unmarshal(body, &replicationControllerList); err != nil {
-		return fmt.Errorf("failed to decode rc resources: %v", err)
+	containerPath := "/spec/template/spec/containers/" + strconv.Itoa(index)
+	patch, err := json.Marshal([]map[string]string{{
+		"op":    "test",
+		"path":  containerPath + "/name",
+		"value": containerName,
+	}, {
+		"op":    "replace",
+		"path":  containerPath + "/image",
+		"value": image + ":" + version,
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to encode patch in json: %v", err)
 	}
-
-	return nil
+	_, err = PatchKubeResource(ctx, c.replicationControllerURL(namespace, name), JSONPatchType, patch, c.Client)
+	return err
 }
 
-func (c *Client) ReplicationControllerList(ctx context.Context, namespace, label string) ([]api.ReplicationController, error) {
+func (c *Client) ReplicationControllerList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.ReplicationController, error) {
 	var replicationControllers []api.ReplicationController
 
-	apiResult, err := ListKubeResources(ctx, &ReplicationControllerResource{c.Host, namespace, label}, c.Client)
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &ReplicationControllerResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
 	if err != nil {
-		return replicationControllers, fmt.Errorf("Resource List failed: %v", err)
+		return replicationControllers, fmt.Errorf("Resource List failed: %w", err)
 	}
 
 	var replicationControllerList api.ReplicationControllerList
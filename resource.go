@@ -2,21 +2,86 @@ package kubeclient
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 
 	"golang.org/x/net/context"
 	"golang.org/x/net/context/ctxhttp"
 )
 
+// KubeResource is a collection of resources, restricted to those matching
+// its label. KubeResourceNamespace is empty for cluster-scoped resources,
+// such as nodes and namespaces, whose KubeResourcesURL has no namespace in
+// its path.
 type KubeResource interface {
 	KubeResourcesURL() string
 	KubeResourceNamespace() string
 	KubeResourceLabel() string
 }
 
+// doRequest sends req with ctx attached to it, so that the transport layers
+// installed on the client can see request scoped values such as priority.
+// Reads of the response body fail with ctx's error once ctx is done, even
+// through transports that do not watch the request's context themselves,
+// so a cancelled call stops downloading at once.
+func doRequest(ctx context.Context, httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	res, err := ctxhttp.Do(ctx, httpClient, req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	res.Body = newContextBody(ctx, res.Body)
+	return res, nil
+}
+
+// contextBody is a response body whose reads fail once ctx is done. A read
+// already blocked is unblocked by closing the underlying body.
+type contextBody struct {
+	ctx      context.Context
+	rc       io.ReadCloser
+	stop     func()
+	stopOnce sync.Once
+}
+
+func newContextBody(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	if ctx.Done() == nil {
+		// The context can never be done.
+		return rc
+	}
+	body := &contextBody{ctx: ctx, rc: rc, stop: closeOnDone(ctx, rc)}
+	if w, ok := rc.(io.Writer); ok {
+		// Keep upgraded connections, such as Exec's websocket, writable.
+		return struct {
+			*contextBody
+			io.Writer
+		}{body, w}
+	}
+	return body
+}
+
+func (b *contextBody) Read(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := b.rc.Read(p)
+	if err != nil && err != io.EOF && b.ctx.Err() != nil {
+		// Report why the body was closed rather than that it was.
+		err = b.ctx.Err()
+	}
+	return n, err
+}
+
+func (b *contextBody) Close() error {
+	b.stopOnce.Do(b.stop)
+	return b.rc.Close()
+}
+
 func CreateKubeResource(ctx context.Context,
 	kubeResource KubeResource,
 	kubeResourceJSON bytes.Buffer,
@@ -27,7 +92,7 @@ func CreateKubeResource(ctx context.Context,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: POST %q : %v", postURL, err)
 	}
-	res, err := ctxhttp.Do(ctx, httpClient, req)
+	res, err := doRequest(ctx, httpClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: POST %q: %v", postURL, err)
 	}
@@ -37,41 +102,309 @@ func CreateKubeResource(ctx context.Context,
 		return nil, fmt.Errorf("failed to read request body for POST %q: %v", postURL, err)
 	}
 	if res.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("http error: %d POST %q: %q: %v", res.StatusCode, postURL, string(body), err)
+		err := newStatusError("POST", postURL, res.StatusCode, body)
+		return nil, unavailableError(ctx, httpClient, postURL, res.StatusCode, err)
 	}
 
 	return body, nil
 }
 
+func GetKubeResource(ctx context.Context, url string, httpClient *http.Client) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: GET %q : %v", url, err)
+	}
+	res, err := doRequest(ctx, httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: GET %q: %v", url, err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: GET %q: %v", url, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		err := newStatusError("GET", url, res.StatusCode, body)
+		return nil, unavailableError(ctx, httpClient, url, res.StatusCode, err)
+	}
+	return body, nil
+}
+
+func UpdateKubeResource(ctx context.Context, url string, kubeResourceJSON bytes.Buffer, httpClient *http.Client) ([]byte, error) {
+	req, err := http.NewRequest("PUT", url, &kubeResourceJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: PUT %q : %v", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := doRequest(ctx, httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: PUT %q: %v", url, err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: PUT %q: %v", url, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		err := newStatusError("PUT", url, res.StatusCode, body)
+		return nil, unavailableError(ctx, httpClient, url, res.StatusCode, err)
+	}
+	return body, nil
+}
+
+// PatchType is the format of a patch, sent as its content type.
+type PatchType string
+
+const (
+	// JSONPatchType is an RFC 6902 list of operations.
+	JSONPatchType PatchType = "application/json-patch+json"
+	// MergePatchType is an RFC 7386 merge patch; lists are replaced whole.
+	MergePatchType PatchType = "application/merge-patch+json"
+	// StrategicMergePatchType is a merge patch that merges lists of
+	// built-in types by key, such as containers by name.
+	StrategicMergePatchType PatchType = "application/strategic-merge-patch+json"
+)
+
+// PatchKubeResource applies body, a patch in the format given by
+// patchType, to the resource at url and returns the patched resource.
+func PatchKubeResource(ctx context.Context, url string, patchType PatchType, body []byte, httpClient *http.Client) ([]byte, error) {
+	req, err := http.NewRequest("PATCH", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: PATCH %q : %v", url, err)
+	}
+	req.Header.Set("Content-Type", string(patchType))
+	res, err := doRequest(ctx, httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: PATCH %q: %v", url, err)
+	}
+	result, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: PATCH %q: %v", url, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		err := newStatusError("PATCH", url, res.StatusCode, result)
+		return nil, unavailableError(ctx, httpClient, url, res.StatusCode, err)
+	}
+	return result, nil
+}
+
+// mergePatchKubeResource applies patch, encoded as a JSON merge patch, to the
+// resource at url and returns the patched resource.
+func mergePatchKubeResource(ctx context.Context, url string, patch interface{}, httpClient *http.Client) ([]byte, error) {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode patch in json: %v", err)
+	}
+	return PatchKubeResource(ctx, url, MergePatchType, body, httpClient)
+}
+
 func DeleteKubeResource(ctx context.Context, url string, httpClient *http.Client) error {
-	req, err := http.NewRequest("DELETE", url, nil)
+	return deleteKubeResource(ctx, url, nil, httpClient)
+}
+
+// Propagation policies, which decide what becomes of an object's dependents,
+// such as a replication controller's pods, when it is deleted.
+const (
+	// DeletePropagationOrphan leaves the dependents in place.
+	DeletePropagationOrphan = "Orphan"
+	// DeletePropagationBackground deletes the object at once and has the
+	// garbage collector delete the dependents afterwards.
+	DeletePropagationBackground = "Background"
+	// DeletePropagationForeground keeps the object, marked for deletion,
+	// until the garbage collector has deleted the dependents that block it.
+	DeletePropagationForeground = "Foreground"
+)
+
+// DeleteOptions controls how an object is deleted. The typed delete methods
+// accept one as an optional last argument.
+type DeleteOptions struct {
+	// GracePeriodSeconds overrides the object's grace period; for pods,
+	// the time their containers have to stop. Zero deletes a pod at once,
+	// without waiting for its kubelet to confirm that it has stopped.
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty"`
+	// PropagationPolicy is one of the DeletePropagation policies. Empty
+	// leaves the choice to the resource's default.
+	PropagationPolicy string `json:"propagationPolicy,omitempty"`
+	// Preconditions, if set, must hold for the delete to go ahead;
+	// otherwise it fails with a conflict.
+	Preconditions *Preconditions `json:"preconditions,omitempty"`
+}
+
+// Preconditions guard a delete against the object having been replaced
+// (UID) or changed (ResourceVersion) since it was read.
+type Preconditions struct {
+	UID             string `json:"uid,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+func firstDeleteOptions(opts []DeleteOptions) *DeleteOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	return &opts[0]
+}
+
+// DeleteKubeResourceWithOptions is DeleteKubeResource controlled by opts.
+func DeleteKubeResourceWithOptions(ctx context.Context, url string, opts DeleteOptions, httpClient *http.Client) error {
+	return deleteKubeResource(ctx, url, &opts, httpClient)
+}
+
+// deleteKubeResource deletes the resource at url, sending options, if not
+// nil, in the request body.
+func deleteKubeResource(ctx context.Context, url string, options *DeleteOptions, httpClient *http.Client) error {
+	_, err := deleteRequest(ctx, url, options, httpClient)
+	return err
+}
+
+// DeleteKubeResourceCollection deletes every object in the collection of
+// kubeResource that its label selector matches, and returns the list of
+// deleted objects. The selector must not be empty: an unlabelled resource
+// would otherwise delete the whole collection.
+func DeleteKubeResourceCollection(ctx context.Context, kubeResource KubeResource, opts DeleteOptions, httpClient *http.Client) ([]byte, error) {
+	collectionURL, err := url.Parse(kubeResource.KubeResourcesURL())
+	if err != nil {
+		return nil, err
+	}
+	label, err := resourceSelector(kubeResource)
+	if err != nil {
+		return nil, err
+	}
+	if label == "" {
+		return nil, errors.New("refusing to delete a collection without a label selector")
+	}
+	collectionURL.RawQuery = url.Values{"labelSelector": {label}}.Encode()
+	return deleteRequest(ctx, collectionURL.String(), &opts, httpClient)
+}
+
+// deleteRequest sends a DELETE to url, with options, if not nil, in the
+// request body, and returns the response body.
+func deleteRequest(ctx context.Context, url string, options *DeleteOptions, httpClient *http.Client) ([]byte, error) {
+	var optionsBody io.Reader
+	if options != nil {
+		optionsJSON, err := json.Marshal(struct {
+			Kind       string `json:"kind"`
+			APIVersion string `json:"apiVersion"`
+			*DeleteOptions
+		}{"DeleteOptions", "v1", options})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode delete options in json: %v", err)
+		}
+		optionsBody = bytes.NewReader(optionsJSON)
+	}
+	req, err := http.NewRequest("DELETE", url, optionsBody)
 	if err != nil {
-		return fmt.Errorf("failed to create request: DELETE %q : %v", url, err)
+		return nil, fmt.Errorf("failed to create request: DELETE %q : %v", url, err)
+	}
+	if options != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	res, err := ctxhttp.Do(ctx, httpClient, req)
+	res, err := doRequest(ctx, httpClient, req)
 	if err != nil {
-		return fmt.Errorf("failed to make request: DELETE %q: %v", url, err)
+		return nil, fmt.Errorf("failed to make request: DELETE %q: %v", url, err)
 	}
 	body, err := ioutil.ReadAll(res.Body)
 	res.Body.Close()
 	if err != nil {
-		return fmt.Errorf("failed to read response body: DELETE %q: %v", url, err)
+		return nil, fmt.Errorf("failed to read response body: DELETE %q: %v", url, err)
 	}
 	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("http error: %d DELETE %q: %q: %v", res.StatusCode, url, string(body), err)
+		err := newStatusError("DELETE", url, res.StatusCode, body)
+		return nil, unavailableError(ctx, httpClient, url, res.StatusCode, err)
 	}
-	return nil
+	return body, nil
 }
 
 func ListKubeResources(ctx context.Context, kubeResource KubeResource, httpClient *http.Client) ([]byte, error) {
+	return listKubeResources(ctx, kubeResource, url.Values{}, httpClient)
+}
+
+// ListOptions narrows a list beyond its resource's label selector. The
+// typed list methods accept one as an optional last argument.
+type ListOptions struct {
+	// FieldSelector selects objects by the values of their fields, e.g.
+	// "status.phase=Running,spec.nodeName=node-1". Each resource supports
+	// only some fields; metadata.name and metadata.namespace work for all.
+	FieldSelector string
+	// Limit, if positive, asks for at most this many objects; the rest
+	// are fetched by listing again with the continue token returned by
+	// ListKubeResourcesPage.
+	Limit int
+	// Continue is the token returned with the previous page of a list.
+	Continue string
+}
+
+func (opts ListOptions) values() url.Values {
+	values := url.Values{}
+	if opts.FieldSelector != "" {
+		values.Set("fieldSelector", opts.FieldSelector)
+	}
+	if opts.Limit > 0 {
+		values.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Continue != "" {
+		values.Set("continue", opts.Continue)
+	}
+	return values
+}
+
+// firstListOptions returns the options passed to a typed list method, if
+// any.
+func firstListOptions(opts []ListOptions) ListOptions {
+	if len(opts) == 0 {
+		return ListOptions{}
+	}
+	return opts[0]
+}
+
+// ListKubeResourcesWithOptions is ListKubeResources narrowed by opts.
+func ListKubeResourcesWithOptions(ctx context.Context, kubeResource KubeResource, opts ListOptions, httpClient *http.Client) ([]byte, error) {
+	return listKubeResources(ctx, kubeResource, opts.values(), httpClient)
+}
+
+// listMeta holds the list metadata fields that the api package predates.
+type listMeta struct {
+	Metadata struct {
+		Continue string `json:"continue"`
+	} `json:"metadata"`
+}
+
+// ListKubeResourcesPage lists a page of at most opts.Limit resources,
+// starting at opts.Continue ("" for the first page). It returns the raw
+// page and the continue token for the next one, which is empty after the
+// last page. Pages are drawn from a consistent snapshot; if the snapshot
+// has expired by the time a page is asked for, the apiserver fails the
+// request with 410 Gone and the list must be started over.
+func ListKubeResourcesPage(ctx context.Context, kubeResource KubeResource, opts ListOptions, httpClient *http.Client) ([]byte, string, error) {
+	results, err := listKubeResources(ctx, kubeResource, opts.values(), httpClient)
+	if err != nil {
+		return results, "", err
+	}
+	var meta listMeta
+	if err := json.Unmarshal(results, &meta); err != nil {
+		return results, "", fmt.Errorf("failed to decode list metadata: %v", err)
+	}
+	return results, meta.Metadata.Continue, nil
+}
+
+// listKubeResourcesPage lists at most limit resources, starting at the
+// continue token returned with the previous page ("" for the first page).
+func listKubeResourcesPage(ctx context.Context, kubeResource KubeResource, limit int, continueToken string, httpClient *http.Client) ([]byte, string, error) {
+	return ListKubeResourcesPage(ctx, kubeResource, ListOptions{Limit: limit, Continue: continueToken}, httpClient)
+}
+
+func listKubeResources(ctx context.Context, kubeResource KubeResource, values url.Values, httpClient *http.Client) ([]byte, error) {
 	var results []byte
 	kubeResourceURL, err := url.Parse(kubeResource.KubeResourcesURL())
 	if err != nil {
 		return results, err
 	}
 
-	values := url.Values{}
-	values.Set("labelSelector", kubeResource.KubeResourceLabel())
+	label, err := resourceSelector(kubeResource)
+	if err != nil {
+		return results, err
+	}
+	values.Set("labelSelector", label)
 	kubeResourceURL.RawQuery = values.Encode()
 
 	url := kubeResourceURL.String()
@@ -79,7 +412,7 @@ func ListKubeResources(ctx context.Context, kubeResource KubeResource, httpClien
 	if err != nil {
 		return results, fmt.Errorf("failed to create request: GET %q : %v", url, err)
 	}
-	res, err := ctxhttp.Do(ctx, httpClient, req)
+	res, err := doRequest(ctx, httpClient, req)
 	if err != nil {
 		return results, fmt.Errorf("failed to make request: GET %q: %v", url, err)
 	}
@@ -89,7 +422,8 @@ func ListKubeResources(ctx context.Context, kubeResource KubeResource, httpClien
 		return results, fmt.Errorf("failed to read response body: GET %q: %v", url, err)
 	}
 	if res.StatusCode != http.StatusOK {
-		return results, fmt.Errorf("http error %d GET %q: %q: %v", res.StatusCode, url, string(results), err)
+		err := newStatusError("GET", url, res.StatusCode, results)
+		return results, unavailableError(ctx, httpClient, url, res.StatusCode, err)
 	}
 
 	return results, nil
@@ -0,0 +1,185 @@
+package kubeclient
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// ResponseStats describes the body of a single response, for finding the
+// requests, and the callers making them, behind spikes in memory use:
+// callers read whole bodies into memory before decoding them.
+type ResponseStats struct {
+	StatsKey
+	// Tag is the tag the request's context was given with WithStatsTag.
+	Tag string
+	// Bytes is the size of the body read, after any decompression.
+	Bytes int64
+	// Objects is the number of objects in the body read: the items of a
+	// list, the events of a watch, and 1 for a single object.
+	Objects int64
+}
+
+type statsTagKey struct{}
+
+// WithStatsTag returns a copy of ctx that tags the ResponseStats of every
+// request made with it with tag, such as the name of the job making them.
+func WithStatsTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, statsTagKey{}, tag)
+}
+
+// StatsTagFromContext returns the tag ctx was given with WithStatsTag, or
+// "" if it was not tagged.
+func StatsTagFromContext(ctx context.Context) string {
+	tag, _ := ctx.Value(statsTagKey{}).(string)
+	return tag
+}
+
+// UseResponseStats calls fn with the ResponseStats of every response
+// received through c, once its body is closed. Objects are counted as the
+// body streams by, without holding on to it, but at some cost in CPU, so
+// this is meant to be turned on while looking for a problem. fn may be
+// called from many goroutines at once.
+func (c *Client) UseResponseStats(fn func(ResponseStats)) {
+	c.Client.Transport = &responseStatsTransport{fn: fn, rt: c.Client.Transport}
+}
+
+type responseStatsTransport struct {
+	fn func(ResponseStats)
+	rt http.RoundTripper
+}
+
+func (t *responseStatsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	key := requestKey(req)
+	body := &countingBody{
+		rc:      res.Body,
+		counter: objectCounter{list: key.Verb == "LIST"},
+		stats:   ResponseStats{StatsKey: key, Tag: StatsTagFromContext(req.Context())},
+		fn:      t.fn,
+	}
+	// RoundTrippers must not modify the response they are given.
+	r := new(http.Response)
+	*r = *res
+	r.Body = body
+	return r, nil
+}
+
+func (t *responseStatsTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
+
+// countingBody counts the bytes and objects read from a response body and
+// reports them when it is closed.
+type countingBody struct {
+	rc      io.ReadCloser
+	counter objectCounter
+	stats   ResponseStats
+	fn      func(ResponseStats)
+	once    sync.Once
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	b.stats.Bytes += int64(n)
+	b.counter.scan(p[:n])
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.rc.Close()
+	b.once.Do(func() {
+		b.stats.Objects = b.counter.objects
+		b.fn(b.stats)
+	})
+	return err
+}
+
+// objectCounter counts the JSON objects in a stream fed to it in pieces:
+// the elements of the top-level "items" array of a list, or else the
+// top-level objects, one per watch event or one for a single object.
+type objectCounter struct {
+	list    bool
+	objects int64
+
+	depth    int
+	inString bool
+	escaped  bool
+	// key holds the start of the string being read at depth 1, enough to
+	// recognize "items"; long is set once it no longer fits.
+	key       []byte
+	long      bool
+	lastStr   string
+	lastKey   string
+	inItems   bool
+	capturing bool
+}
+
+func (c *objectCounter) scan(p []byte) {
+	for _, b := range p {
+		if c.inString {
+			switch {
+			case c.escaped:
+				c.escaped = false
+			case b == '\\':
+				c.escaped = true
+			case b == '"':
+				c.inString = false
+				if c.capturing {
+					c.capturing = false
+					c.lastStr = ""
+					if !c.long {
+						c.lastStr = string(c.key)
+					}
+				}
+				continue
+			}
+			if c.capturing {
+				if len(c.key) < len("items") {
+					c.key = append(c.key, b)
+				} else {
+					c.long = true
+				}
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			c.inString = true
+			if c.depth == 1 {
+				c.capturing = true
+				c.key = c.key[:0]
+				c.long = false
+			}
+		case ':':
+			if c.depth == 1 {
+				c.lastKey = c.lastStr
+			}
+		case ',':
+			if c.depth == 1 {
+				c.lastKey = ""
+			}
+		case '{', '[':
+			if !c.list && c.depth == 0 && b == '{' {
+				c.objects++
+			}
+			if c.list && c.inItems && c.depth == 2 && b == '{' {
+				c.objects++
+			}
+			if c.list && c.depth == 1 && b == '[' && c.lastKey == "items" {
+				c.inItems = true
+			}
+			c.depth++
+		case '}', ']':
+			c.depth--
+			if c.depth == 1 {
+				c.inItems = false
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package kubeclient
+
+import "testing"
+
+func TestObjectCounterScan(t *testing.T) {
+	tests := []struct {
+		name string
+		list bool
+		in   string
+		want int64
+	}{
+		{"object", false, `{"kind":"Pod","metadata":{"name":"web"}}`, 1},
+		{"watch events", false, `{"type":"ADDED","object":{}}` + "\n" + `{"type":"MODIFIED","object":{}}` + "\n", 2},
+		{"empty list", true, `{"kind":"PodList","items":[]}`, 0},
+		{"null items", true, `{"kind":"PodList","items":null}`, 0},
+		{"list", true, `{"kind":"PodList","metadata":{},"items":[{"a":{}},{"b":[{}]},{}]}`, 3},
+		{"items after other arrays", true, `{"other":[{},{}],"items":[{},{}]}`, 2},
+		{"nested items key", true, `{"metadata":{"items":[{}]},"items":[{}]}`, 1},
+		{"items as value", true, `{"kind":"items","other":[{}],"items":[{}]}`, 1},
+		{"longer key", true, `{"itemsx":[{},{}],"items":[{}]}`, 1},
+		{"braces in strings", true, `{"items":[{"a":"{[}]"},{"b":"\"}{"}]}`, 2},
+		{"escaped backslash", true, `{"items":[{"a":"\\"},{"b":"\\\"{"}]}`, 2},
+		{"escaped quote in key", true, `{"it\"ems":[{}],"items":[{},{}]}`, 2},
+		{"whitespace", true, "{ \"items\" : [ { } ,\n { } ] }", 2},
+	}
+	for _, tt := range tests {
+		// Feed the input in two pieces split at every offset, so each
+		// boundary falls inside strings, escapes, and keys somewhere.
+		for i := 0; i <= len(tt.in); i++ {
+			c := objectCounter{list: tt.list}
+			c.scan([]byte(tt.in[:i]))
+			c.scan([]byte(tt.in[i:]))
+			if c.objects != tt.want {
+				t.Errorf("%s: split at %d: got %d objects, want %d", tt.name, i, c.objects, tt.want)
+			}
+		}
+		c := objectCounter{list: tt.list}
+		for j := 0; j < len(tt.in); j++ {
+			c.scan([]byte{tt.in[j]})
+		}
+		if c.objects != tt.want {
+			t.Errorf("%s: byte at a time: got %d objects, want %d", tt.name, c.objects, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package kubeclient
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// resyncJitter is the fraction of the interval that Resync adds at random to
+// each wait, so replicas started together do not re-list in lockstep.
+const resyncJitter = 0.2
+
+// Resync calls fn every interval until ctx is done, for example to re-list a
+// resource and repair a cache after missed watch events. Each wait is
+// lengthened by up to 20% at random, and the first call happens after one
+// such wait rather than immediately. Resync blocks and returns ctx.Err().
+func Resync(ctx context.Context, interval time.Duration, fn func(context.Context)) error {
+	return resync(ctx, realClock{}, interval, fn)
+}
+
+func resync(ctx context.Context, clock Clock, interval time.Duration, fn func(context.Context)) error {
+	for {
+		t := clock.NewTimer(Jitter(interval, resyncJitter))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C():
+			fn(ctx)
+		}
+	}
+}
+
+// Jitter returns a random duration between d and d+factor*d.
+func Jitter(d time.Duration, factor float64) time.Duration {
+	if factor <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Float64()*factor*float64(d))
+}
@@ -0,0 +1,140 @@
+package kubeclient
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy retries requests that failed for transient reasons: 429 Too
+// Many Requests, 500, 502, 503, and 504 responses, and connections reset by
+// the peer. Waits between attempts grow exponentially from InitialBackoff up
+// to MaxBackoff, each randomly shortened by up to half to spread retries
+// out, unless the response names a longer wait in its Retry-After header.
+//
+// POST and PATCH requests are not idempotent, so they are only retried on
+// 429, which the apiserver sends before it has processed the request.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns a policy retrying up to 5 times, waiting from
+// 200ms up to 10s between attempts.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:     5,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// backoff returns the wait before the given retry, counting from 1.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryTransport retries requests under the client's RetryPolicy, read
+// afresh for every request so it can be changed at any time.
+type retryTransport struct {
+	client *Client
+	rt     http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.client.RetryPolicy
+	if policy == nil || policy.MaxRetries <= 0 || (req.Body != nil && req.GetBody == nil) {
+		return t.rt.RoundTrip(req)
+	}
+	idempotent := req.Method != "POST" && req.Method != "PATCH"
+	for retry := 1; ; retry++ {
+		res, err := t.rt.RoundTrip(req)
+		if retry > policy.MaxRetries || !retryable(res, err, idempotent) {
+			return res, err
+		}
+		wait := policy.backoff(retry)
+		if res != nil {
+			if after := retryAfter(res, t.client.clock().Now()); after > wait {
+				wait = after
+			}
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := t.client.clock().NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C():
+		}
+		if t.client.stats != nil {
+			t.client.stats.retried(requestKey(req))
+		}
+
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			// RoundTrippers must not modify the request they are given.
+			r := new(http.Request)
+			*r = *req
+			r.Body = body
+			req = r
+		}
+	}
+}
+
+func (t *retryTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
+
+// retryable reports whether a request that ended with res or err should be
+// tried again.
+func retryable(res *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		return idempotent && (errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF))
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// retryAfter returns the wait res asks for in its Retry-After header, given
+// in seconds or as a date, or 0 if it has none. now is the current time,
+// from the client's clock.
+func retryAfter(res *http.Response, now time.Time) time.Duration {
+	header := res.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,253 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	roleBindingsPath        = rbacPrefix + "/namespaces/%s/rolebindings"
+	roleBindingPath         = rbacPrefix + "/namespaces/%s/rolebindings/%s"
+	clusterRoleBindingsPath = rbacPrefix + "/clusterrolebindings"
+	clusterRoleBindingPath  = rbacPrefix + "/clusterrolebindings/%s"
+)
+
+// Kinds of RBAC subjects.
+const (
+	SubjectUser           = "User"
+	SubjectGroup          = "Group"
+	SubjectServiceAccount = "ServiceAccount"
+)
+
+// RoleBinding grants the permissions of a Role, or of a ClusterRole, to
+// its subjects within its namespace.
+type RoleBinding struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Subjects       []Subject `json:"subjects,omitempty"`
+	// RoleRef cannot be changed once the binding is created; delete and
+	// recreate the binding to bind another role.
+	RoleRef RoleRef `json:"roleRef"`
+}
+
+// ClusterRoleBinding grants the permissions of a ClusterRole to its
+// subjects cluster-wide.
+type ClusterRoleBinding struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Subjects       []Subject `json:"subjects,omitempty"`
+	// RoleRef cannot be changed once the binding is created.
+	RoleRef RoleRef `json:"roleRef"`
+}
+
+// Subject is who a binding grants permissions to.
+type Subject struct {
+	// Kind is SubjectUser, SubjectGroup, or SubjectServiceAccount.
+	Kind string `json:"kind"`
+	// APIGroup is "rbac.authorization.k8s.io" for users and groups, and
+	// "" for service accounts.
+	APIGroup string `json:"apiGroup,omitempty"`
+	Name     string `json:"name"`
+	// Namespace is the namespace of a service account.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RoleRef names the role a binding grants.
+type RoleRef struct {
+	// APIGroup is "rbac.authorization.k8s.io".
+	APIGroup string `json:"apiGroup"`
+	// Kind is "Role" or "ClusterRole".
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type RoleBindingResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (rb *RoleBindingResource) KubeResourcesURL() string {
+	return rb.Host + fmt.Sprintf(roleBindingsPath, rb.Namespace)
+}
+
+func (rb *RoleBindingResource) KubeResourceNamespace() string {
+	return rb.Namespace
+}
+
+func (rb *RoleBindingResource) KubeResourceLabel() string {
+	return rb.Label
+}
+
+type ClusterRoleBindingResource struct {
+	Host  string
+	Label string
+}
+
+func (rb *ClusterRoleBindingResource) KubeResourcesURL() string {
+	return rb.Host + clusterRoleBindingsPath
+}
+
+// KubeResourceNamespace returns "" since cluster role bindings are
+// cluster-scoped.
+func (rb *ClusterRoleBindingResource) KubeResourceNamespace() string {
+	return ""
+}
+
+func (rb *ClusterRoleBindingResource) KubeResourceLabel() string {
+	return rb.Label
+}
+
+func (c *Client) CreateRoleBinding(ctx context.Context, binding *RoleBinding) (*RoleBinding, error) {
+	var bindingJSON bytes.Buffer
+	if err := json.NewEncoder(&bindingJSON).Encode(binding); err != nil {
+		return nil, fmt.Errorf("failed to encode role binding in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", binding.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &RoleBindingResource{c.Host, namespace, ""}, bindingJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.roleBindingURL(namespace, binding.Name), binding)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var bindingResult RoleBinding
+	if err := json.Unmarshal(apiResult, &bindingResult); err != nil {
+		return nil, fmt.Errorf("failed to decode role binding resources: %v", err)
+	}
+	return &bindingResult, nil
+}
+
+func (c *Client) GetRoleBinding(ctx context.Context, namespace, name string) (*RoleBinding, error) {
+	apiResult, err := GetKubeResource(ctx, c.roleBindingURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var binding RoleBinding
+	if err := json.Unmarshal(apiResult, &binding); err != nil {
+		return nil, fmt.Errorf("failed to decode role binding json: %v", err)
+	}
+	return &binding, nil
+}
+
+// UpdateRoleBinding replaces the role binding's subjects. Its
+// ResourceVersion guards against overwriting concurrent changes.
+func (c *Client) UpdateRoleBinding(ctx context.Context, binding *RoleBinding) (*RoleBinding, error) {
+	var bindingJSON bytes.Buffer
+	if err := json.NewEncoder(&bindingJSON).Encode(binding); err != nil {
+		return nil, fmt.Errorf("failed to encode role binding in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", binding.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	url := c.roleBindingURL(namespace, binding.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, bindingJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, binding)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var bindingResult RoleBinding
+	if err := json.Unmarshal(apiResult, &bindingResult); err != nil {
+		return nil, fmt.Errorf("failed to decode role binding resources: %v", err)
+	}
+	return &bindingResult, nil
+}
+
+func (c *Client) DeleteRoleBinding(ctx context.Context, namespace, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.roleBindingURL(namespace, name), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) RoleBindingList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]RoleBinding, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &RoleBindingResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var bindingList struct {
+		Items []RoleBinding `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &bindingList); err != nil {
+		return nil, fmt.Errorf("failed to decode role binding resources: %v", err)
+	}
+	return bindingList.Items, nil
+}
+
+func (c *Client) CreateClusterRoleBinding(ctx context.Context, binding *ClusterRoleBinding) (*ClusterRoleBinding, error) {
+	var bindingJSON bytes.Buffer
+	if err := json.NewEncoder(&bindingJSON).Encode(binding); err != nil {
+		return nil, fmt.Errorf("failed to encode cluster role binding in json: %v", err)
+	}
+	apiResult, err := CreateKubeResource(ctx, &ClusterRoleBindingResource{c.Host, ""}, bindingJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.clusterRoleBindingURL(binding.Name), binding)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var bindingResult ClusterRoleBinding
+	if err := json.Unmarshal(apiResult, &bindingResult); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role binding resources: %v", err)
+	}
+	return &bindingResult, nil
+}
+
+func (c *Client) GetClusterRoleBinding(ctx context.Context, name string) (*ClusterRoleBinding, error) {
+	apiResult, err := GetKubeResource(ctx, c.clusterRoleBindingURL(name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var binding ClusterRoleBinding
+	if err := json.Unmarshal(apiResult, &binding); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role binding json: %v", err)
+	}
+	return &binding, nil
+}
+
+// UpdateClusterRoleBinding replaces the cluster role binding's subjects.
+// Its ResourceVersion guards against overwriting concurrent changes.
+func (c *Client) UpdateClusterRoleBinding(ctx context.Context, binding *ClusterRoleBinding) (*ClusterRoleBinding, error) {
+	var bindingJSON bytes.Buffer
+	if err := json.NewEncoder(&bindingJSON).Encode(binding); err != nil {
+		return nil, fmt.Errorf("failed to encode cluster role binding in json: %v", err)
+	}
+	url := c.clusterRoleBindingURL(binding.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, bindingJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, binding)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var bindingResult ClusterRoleBinding
+	if err := json.Unmarshal(apiResult, &bindingResult); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role binding resources: %v", err)
+	}
+	return &bindingResult, nil
+}
+
+func (c *Client) DeleteClusterRoleBinding(ctx context.Context, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.clusterRoleBindingURL(name), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) ClusterRoleBindingList(ctx context.Context, label string, opts ...ListOptions) ([]ClusterRoleBinding, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &ClusterRoleBindingResource{c.Host, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var bindingList struct {
+		Items []ClusterRoleBinding `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &bindingList); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role binding resources: %v", err)
+	}
+	return bindingList.Items, nil
+}
+
+func (c *Client) roleBindingURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(roleBindingPath, namespace, name)
+}
+
+func (c *Client) clusterRoleBindingURL(name string) string {
+	return c.Host + fmt.Sprintf(clusterRoleBindingPath, name)
+}
@@ -0,0 +1,240 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	rbacGroup        = "rbac.authorization.k8s.io"
+	rbacPrefix       = apisPath + "/" + rbacGroup + "/v1"
+	rolesPath        = rbacPrefix + "/namespaces/%s/roles"
+	rolePath         = rbacPrefix + "/namespaces/%s/roles/%s"
+	clusterRolesPath = rbacPrefix + "/clusterroles"
+	clusterRolePath  = rbacPrefix + "/clusterroles/%s"
+)
+
+// Role grants the permissions in its rules within its namespace, once bound
+// to subjects by a RoleBinding.
+type Role struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Rules          []PolicyRule `json:"rules"`
+}
+
+// ClusterRole grants the permissions in its rules cluster-wide when bound
+// by a ClusterRoleBinding, or within a namespace when bound by a
+// RoleBinding there.
+type ClusterRole struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Rules          []PolicyRule `json:"rules"`
+	// AggregationRule, if set, has the controller manager fill in Rules
+	// from the cluster roles it selects, overwriting any set by hand.
+	AggregationRule *AggregationRule `json:"aggregationRule,omitempty"`
+}
+
+// PolicyRule allows Verbs, e.g. "get" or "list", on the named resources of
+// the API groups, or, for cluster roles, on non-resource URLs such as
+// "/healthz". "*" matches anything; the core group is "".
+type PolicyRule struct {
+	Verbs           []string `json:"verbs"`
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+type AggregationRule struct {
+	ClusterRoleSelectors []LabelSelector `json:"clusterRoleSelectors,omitempty"`
+}
+
+type RoleResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (r *RoleResource) KubeResourcesURL() string {
+	return r.Host + fmt.Sprintf(rolesPath, r.Namespace)
+}
+
+func (r *RoleResource) KubeResourceNamespace() string {
+	return r.Namespace
+}
+
+func (r *RoleResource) KubeResourceLabel() string {
+	return r.Label
+}
+
+type ClusterRoleResource struct {
+	Host  string
+	Label string
+}
+
+func (r *ClusterRoleResource) KubeResourcesURL() string {
+	return r.Host + clusterRolesPath
+}
+
+// KubeResourceNamespace returns "" since cluster roles are cluster-scoped.
+func (r *ClusterRoleResource) KubeResourceNamespace() string {
+	return ""
+}
+
+func (r *ClusterRoleResource) KubeResourceLabel() string {
+	return r.Label
+}
+
+func (c *Client) CreateRole(ctx context.Context, role *Role) (*Role, error) {
+	var roleJSON bytes.Buffer
+	if err := json.NewEncoder(&roleJSON).Encode(role); err != nil {
+		return nil, fmt.Errorf("failed to encode role in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", role.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &RoleResource{c.Host, namespace, ""}, roleJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.roleURL(namespace, role.Name), role)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var roleResult Role
+	if err := json.Unmarshal(apiResult, &roleResult); err != nil {
+		return nil, fmt.Errorf("failed to decode role resources: %v", err)
+	}
+	return &roleResult, nil
+}
+
+func (c *Client) GetRole(ctx context.Context, namespace, name string) (*Role, error) {
+	apiResult, err := GetKubeResource(ctx, c.roleURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var role Role
+	if err := json.Unmarshal(apiResult, &role); err != nil {
+		return nil, fmt.Errorf("failed to decode role json: %v", err)
+	}
+	return &role, nil
+}
+
+// UpdateRole replaces the role. Its ResourceVersion guards against
+// overwriting concurrent changes.
+func (c *Client) UpdateRole(ctx context.Context, role *Role) (*Role, error) {
+	var roleJSON bytes.Buffer
+	if err := json.NewEncoder(&roleJSON).Encode(role); err != nil {
+		return nil, fmt.Errorf("failed to encode role in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", role.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	url := c.roleURL(namespace, role.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, roleJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, role)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var roleResult Role
+	if err := json.Unmarshal(apiResult, &roleResult); err != nil {
+		return nil, fmt.Errorf("failed to decode role resources: %v", err)
+	}
+	return &roleResult, nil
+}
+
+func (c *Client) DeleteRole(ctx context.Context, namespace, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.roleURL(namespace, name), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) RoleList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]Role, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &RoleResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var roleList struct {
+		Items []Role `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &roleList); err != nil {
+		return nil, fmt.Errorf("failed to decode role resources: %v", err)
+	}
+	return roleList.Items, nil
+}
+
+func (c *Client) CreateClusterRole(ctx context.Context, role *ClusterRole) (*ClusterRole, error) {
+	var roleJSON bytes.Buffer
+	if err := json.NewEncoder(&roleJSON).Encode(role); err != nil {
+		return nil, fmt.Errorf("failed to encode cluster role in json: %v", err)
+	}
+	apiResult, err := CreateKubeResource(ctx, &ClusterRoleResource{c.Host, ""}, roleJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.clusterRoleURL(role.Name), role)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var roleResult ClusterRole
+	if err := json.Unmarshal(apiResult, &roleResult); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role resources: %v", err)
+	}
+	return &roleResult, nil
+}
+
+func (c *Client) GetClusterRole(ctx context.Context, name string) (*ClusterRole, error) {
+	apiResult, err := GetKubeResource(ctx, c.clusterRoleURL(name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var role ClusterRole
+	if err := json.Unmarshal(apiResult, &role); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role json: %v", err)
+	}
+	return &role, nil
+}
+
+// UpdateClusterRole replaces the cluster role. Its ResourceVersion guards
+// against overwriting concurrent changes.
+func (c *Client) UpdateClusterRole(ctx context.Context, role *ClusterRole) (*ClusterRole, error) {
+	var roleJSON bytes.Buffer
+	if err := json.NewEncoder(&roleJSON).Encode(role); err != nil {
+		return nil, fmt.Errorf("failed to encode cluster role in json: %v", err)
+	}
+	url := c.clusterRoleURL(role.Name)
+	apiResult, err := UpdateKubeResource(ctx, url, roleJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, role)
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var roleResult ClusterRole
+	if err := json.Unmarshal(apiResult, &roleResult); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role resources: %v", err)
+	}
+	return &roleResult, nil
+}
+
+func (c *Client) DeleteClusterRole(ctx context.Context, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.clusterRoleURL(name), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) ClusterRoleList(ctx context.Context, label string, opts ...ListOptions) ([]ClusterRole, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &ClusterRoleResource{c.Host, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var roleList struct {
+		Items []ClusterRole `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &roleList); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster role resources: %v", err)
+	}
+	return roleList.Items, nil
+}
+
+func (c *Client) roleURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(rolePath, namespace, name)
+}
+
+func (c *Client) clusterRoleURL(name string) string {
+	return c.Host + fmt.Sprintf(clusterRolePath, name)
+}
@@ -0,0 +1,178 @@
+package kubeclient
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// RollingUpdateReplicasAnnotation records, on the new replication
+// controller of a rolling update, the number of replicas it is to end up
+// with, so that an interrupted update resumes towards the same size after
+// the old controller has been partly scaled down.
+const RollingUpdateReplicasAnnotation = "kubeclient/rolling-update-replicas"
+
+// RollingUpdateOptions configures RollingUpdateReplicationController.
+type RollingUpdateOptions struct {
+	// Step is the number of pods replaced at a time. It defaults to 1.
+	Step int
+	// PollInterval is how often the new pods are checked for readiness.
+	// It defaults to 2 seconds.
+	PollInterval time.Duration
+}
+
+// RollingUpdateReplicationController replaces the replication controller
+// with newSpec without dropping capacity, as kubectl rolling-update does.
+// newSpec must have another name, and a selector, such as one including a
+// version label, that the old controller's selector does not match the new
+// pods by. It is created with no replicas, then scaled up opts.Step pods
+// at a time; after the new pods of each step are ready, the old controller
+// is scaled down by the same amount. The new controller ends up with
+// newSpec's replicas, or the old controller's if newSpec has none, and the
+// old controller is deleted.
+// If the new controller already exists, the update resumes from its
+// current size towards the size recorded in its
+// RollingUpdateReplicasAnnotation when it was created; newSpec must then
+// set replicas if the annotation is missing. If ctx is done or a step
+// fails, the update stops where it is and both controllers are left in
+// place, so it can be resumed, or rolled back by updating the other way.
+func (c *Client) RollingUpdateReplicationController(ctx context.Context, namespace, name string, newSpec *api.ReplicationController, opts RollingUpdateOptions) (*api.ReplicationController, error) {
+	if opts.Step <= 0 {
+		opts.Step = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultMigratePollInterval
+	}
+
+	old, err := c.GetReplicationController(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if newSpec.Name == "" || newSpec.Name == name {
+		return nil, fmt.Errorf("the new replication controller needs a name other than %s", name)
+	}
+	if newSpec.Spec.Template == nil {
+		return nil, fmt.Errorf("replication controller %s has no pod template", newSpec.Name)
+	}
+	newSelector := newSpec.Spec.Selector
+	if len(newSelector) == 0 {
+		newSelector = newSpec.Spec.Template.Labels
+	}
+	oldSelector := old.Spec.Selector
+	if len(oldSelector) == 0 && old.Spec.Template != nil {
+		oldSelector = old.Spec.Template.Labels
+	}
+	if selectorMatches(oldSelector, newSpec.Spec.Template.Labels) {
+		return nil, fmt.Errorf("the selector of replication controller %s matches the new pods; give them a distinguishing label", name)
+	}
+
+	target := newSpec.Spec.Replicas
+	if target <= 0 {
+		target = old.Spec.Replicas
+	}
+	rc := *newSpec
+	rc.Namespace = namespace
+	rc.Spec.Selector = newSelector
+	rc.Spec.Replicas = 0
+	rc.Annotations = make(map[string]string, len(newSpec.Annotations)+1)
+	for k, v := range newSpec.Annotations {
+		rc.Annotations[k] = v
+	}
+	rc.Annotations[RollingUpdateReplicasAnnotation] = strconv.Itoa(target)
+	created, err := c.CreateReplicationController(ctx, &rc)
+	if IsAlreadyExists(err) {
+		created, err = c.GetReplicationController(ctx, namespace, newSpec.Name)
+		if err == nil && newSpec.Spec.Replicas <= 0 {
+			// The old controller may already have been scaled down, so
+			// only the recorded target is reliable.
+			recorded, convErr := strconv.Atoi(created.Annotations[RollingUpdateReplicasAnnotation])
+			if convErr != nil || recorded <= 0 {
+				return nil, fmt.Errorf("replication controller %s has no valid %s annotation to resume towards; set the replicas of the new spec", newSpec.Name, RollingUpdateReplicasAnnotation)
+			}
+			target = recorded
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	newReplicas, oldReplicas := created.Spec.Replicas, old.Spec.Replicas
+	for newReplicas < target || oldReplicas > 0 {
+		if newReplicas < target {
+			newReplicas += opts.Step
+			if newReplicas > target {
+				newReplicas = target
+			}
+			if err := c.ScaleReplicationController(ctx, namespace, newSpec.Name, newReplicas); err != nil {
+				return nil, fmt.Errorf("failed to scale replication controller %s to %d: %w", newSpec.Name, newReplicas, err)
+			}
+			if err := c.awaitReadyPods(ctx, namespace, newSelector, newReplicas, opts.PollInterval); err != nil {
+				return nil, err
+			}
+		}
+		if oldReplicas > 0 {
+			oldReplicas -= opts.Step
+			if oldReplicas < 0 {
+				oldReplicas = 0
+			}
+			if err := c.ScaleReplicationController(ctx, namespace, name, oldReplicas); err != nil {
+				return nil, fmt.Errorf("failed to scale replication controller %s to %d: %w", name, oldReplicas, err)
+			}
+		}
+	}
+
+	if err := c.DeleteReplicationController(ctx, namespace, name); err != nil {
+		return nil, err
+	}
+	return c.GetReplicationController(ctx, namespace, newSpec.Name)
+}
+
+// awaitReadyPods polls the pods matching selector until at least want of
+// them are ready, or ctx is done.
+func (c *Client) awaitReadyPods(ctx context.Context, namespace string, selector map[string]string, want int, interval time.Duration) error {
+	ticker := c.clock().NewTicker(interval)
+	defer ticker.Stop()
+	label := selectorString(selector)
+	for {
+		pods, err := c.PodList(ctx, namespace, label)
+		if err != nil {
+			return err
+		}
+		ready := 0
+		for i := range pods {
+			pod := &pods[i]
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			if cond := GetPodCondition(pod, api.PodReady); cond != nil && cond.Status == api.ConditionTrue {
+				ready++
+			}
+		}
+		if ready >= want {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d of %d pods matching %s are ready: %v", ready, want, label, ctx.Err())
+		case <-ticker.C():
+		}
+	}
+}
+
+// selectorMatches reports whether the equality based selector matches
+// labels. An empty selector matches nothing, as replication controllers
+// treat it.
+func selectorMatches(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// Scale is the scale subresource of a workload, which reads and sets its
+// replica count without touching the rest of its spec. The api package
+// predates it.
+type Scale struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Spec           ScaleSpec   `json:"spec,omitempty"`
+	Status         ScaleStatus `json:"status,omitempty"`
+}
+
+type ScaleSpec struct {
+	Replicas int `json:"replicas"`
+}
+
+type ScaleStatus struct {
+	// Replicas is the number of replicas the workload's controller last
+	// observed.
+	Replicas int `json:"replicas"`
+	// Selector is the label selector of the workload's pods.
+	Selector string `json:"selector,omitempty"`
+}
+
+// GetScale gets the scale subresource of the named object in the collection
+// of resource, which may be any resource with one, e.g. a
+// ReplicationControllerResource, DeploymentResource, StatefulSetResource,
+// or an APIResource for ReplicaSets.
+func (c *Client) GetScale(ctx context.Context, resource KubeResource, name string) (*Scale, error) {
+	apiResult, err := GetKubeResource(ctx, scaleURL(resource, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var scale Scale
+	if err := json.Unmarshal(apiResult, &scale); err != nil {
+		return nil, fmt.Errorf("failed to decode scale json: %v", err)
+	}
+	return &scale, nil
+}
+
+// Scale sets the replica count of the named object in the collection of
+// resource through its scale subresource, as for GetScale, and returns the
+// updated scale.
+func (c *Client) Scale(ctx context.Context, resource KubeResource, name string, replicas int) (*Scale, error) {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": replicas},
+	}
+	apiResult, err := mergePatchKubeResource(ctx, scaleURL(resource, name), patch, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Patch failed: %w", err)
+	}
+	var scale Scale
+	if err := json.Unmarshal(apiResult, &scale); err != nil {
+		return nil, fmt.Errorf("failed to decode scale json: %v", err)
+	}
+	return &scale, nil
+}
+
+// ScaleReplicationController sets the number of replicas of the replication
+// controller through its scale subresource, leaving the rest of its spec
+// untouched.
+func (c *Client) ScaleReplicationController(ctx context.Context, namespace, name string, replicas int) error {
+	_, err := c.Scale(ctx, &ReplicationControllerResource{c.Host, namespace, ""}, name, replicas)
+	return err
+}
+
+func scaleURL(resource KubeResource, name string) string {
+	return resource.KubeResourcesURL() + "/" + name + "/scale"
+}
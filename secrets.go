@@ -9,7 +9,6 @@ import (
 
 	"golang.org/x/build/kubernetes/api"
 	"golang.org/x/net/context"
-	"golang.org/x/net/context/ctxhttp"
 )
 
 const (
@@ -17,38 +16,60 @@ const (
 )
 
 func (c *Client) CreateSecret(ctx context.Context, secret *api.Secret) (*api.Secret, error) {
+	if err := validateSecretSize(secret); err != nil {
+		return nil, err
+	}
 	var secretJSON bytes.Buffer
 	if err := json.NewEncoder(&secretJSON).Encode(secret); err != nil {
 		return nil, fmt.Errorf("failed to encode secret in json: %v", err)
 	}
-	secretURL := c.secretURL(secret.Namespace)
-	req, err := http.NewRequest("POST", secretURL, &secretJSON)
+	namespace, err := c.resolveNamespace("", secret.Namespace)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: POST %q : %v", secretURL, err)
+		return nil, err
 	}
-	res, err := ctxhttp.Do(ctx, c.Client, req)
+	apiResult, err := CreateKubeResource(ctx, &SecretResource{c.Host, namespace, ""}, secretJSON, c.Client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: POST %q: %v", secretURL, err)
+		err = c.conflictError(ctx, err, c.secretURL(namespace)+"/"+secret.Name, secret)
+		return nil, fmt.Errorf("Create failed: %w", err)
 	}
-	body, err := ioutil.ReadAll(res.Body)
-	res.Body.Close()
+	var secretResult api.Secret
+	if err := json.Unmarshal(apiResult, &secretResult); err != nil {
+		return nil, fmt.Errorf("failed to decode secret resources: %v", err)
+	}
+	return &secretResult, nil
+}
+
+// UpdateSecret replaces the specified Kubernetes secret. The secret's
+// ResourceVersion guards against overwriting concurrent changes.
+func (c *Client) UpdateSecret(ctx context.Context, secret *api.Secret) (*api.Secret, error) {
+	if err := validateSecretSize(secret); err != nil {
+		return nil, err
+	}
+	var secretJSON bytes.Buffer
+	if err := json.NewEncoder(&secretJSON).Encode(secret); err != nil {
+		return nil, fmt.Errorf("failed to encode secret in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", secret.Namespace)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read request body for POST %q: %v", secretURL, err)
+		return nil, err
 	}
-	if res.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("http error: %d POST %q: %q: %v", res.StatusCode, secretURL, string(body), err)
+	url := c.secretURL(namespace) + "/" + secret.Name
+	apiResult, err := UpdateKubeResource(ctx, url, secretJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, url, secret)
+		return nil, fmt.Errorf("Update failed: %w", err)
 	}
 	var secretResult api.Secret
-	if err := json.Unmarshal(body, &secretResult); err != nil {
+	if err := json.Unmarshal(apiResult, &secretResult); err != nil {
 		return nil, fmt.Errorf("failed to decode secret resources: %v", err)
 	}
 	return &secretResult, nil
 }
 
 // DeleteSecret deletes the specified Kubernetes pod.
-func (c *Client) DeleteSecret(ctx context.Context, namespace, secretName string) error {
+func (c *Client) DeleteSecret(ctx context.Context, namespace, secretName string, opts ...DeleteOptions) error {
 	url := c.secretURL(namespace) + "/" + secretName
-	return DeleteKubeResource(ctx, url, c.Client)
+	return deleteKubeResource(ctx, url, firstDeleteOptions(opts), c.Client)
 }
 
 // GetSecret gets the specified Kubernetes pod.
@@ -59,7 +80,7 @@ func (c *Client) GetSecret(ctx context.Context, namespace, secretName string) (*
 	if err != nil {
 		return &secret, fmt.Errorf("failed to create request: GET %q : %v", url, err)
 	}
-	res, err := ctxhttp.Do(ctx, c.Client, req)
+	res, err := doRequest(ctx, c.Client, req)
 	if err != nil {
 		return &secret, fmt.Errorf("failed to make request: GET %q: %v", url, err)
 	}
@@ -69,7 +90,7 @@ func (c *Client) GetSecret(ctx context.Context, namespace, secretName string) (*
 		return &secret, fmt.Errorf("failed to read response body: GET %q: %v", url, err)
 	}
 	if res.StatusCode != http.StatusOK {
-		return &secret, fmt.Errorf("http error: %d GET %q: %q: %v", res.StatusCode, url, string(body), err)
+		return &secret, newStatusError("GET", url, res.StatusCode, body)
 	}
 	if err := json.Unmarshal(body, &secret); err != nil {
 		return &secret, fmt.Errorf("failed to decode secret json: %v", err)
@@ -78,6 +99,39 @@ func (c *Client) GetSecret(ctx context.Context, namespace, secretName string) (*
 	return &secret, nil
 }
 
+func (c *Client) SecretList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.Secret, error) {
+	var secrets []api.Secret
+
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &SecretResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return secrets, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var secretList api.SecretList
+	if err := json.Unmarshal(apiResult, &secretList); err != nil {
+		return secrets, fmt.Errorf("failed to decode secret resources: %v", err)
+	}
+
+	return secretList.Items, nil
+}
+
+type SecretResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (secret *SecretResource) KubeResourcesURL() string {
+	return secret.Host + fmt.Sprintf(secretPath, secret.Namespace)
+}
+
+func (secret *SecretResource) KubeResourceNamespace() string {
+	return secret.Namespace
+}
+
+func (secret *SecretResource) KubeResourceLabel() string {
+	return secret.Label
+}
+
 func (c *Client) secretURL(namespace string) string {
 	return c.Host + fmt.Sprintf(secretPath, namespace)
 }
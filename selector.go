@@ -0,0 +1,267 @@
+package kubeclient
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// labelNameRE matches a label name, or the name part of a prefixed
+	// key.
+	labelNameRE = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	// labelPrefixRE matches the DNS subdomain prefix of a label key.
+	labelPrefixRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// setRequirementRE matches "key in (a,b)" and "key notin (a,b)".
+	setRequirementRE = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+)
+
+// Selector is a label selector built from requirements that must all hold.
+// Its methods validate their arguments and return the selector, so they can
+// be chained:
+//
+//	sel := NewSelector().MatchLabels(map[string]string{"app": "web"}).In("tier", "backend", "cache")
+//	pods, err := c.PodList(ctx, namespace, sel.String())
+//
+// Invalid keys or values are recorded and reported by Err; listing or
+// watching with a selector that does not parse fails rather than matching
+// everything.
+type Selector struct {
+	requirements []selectorRequirement
+	err          error
+}
+
+type selectorRequirement struct {
+	key    string
+	op     string
+	values []string
+}
+
+// NewSelector returns an empty selector, which matches everything.
+func NewSelector() *Selector {
+	return &Selector{}
+}
+
+// MatchLabels requires each key to have the given value.
+func (s *Selector) MatchLabels(labels map[string]string) *Selector {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s.add(k, "=", labels[k])
+	}
+	return s
+}
+
+// Equals requires key to have value.
+func (s *Selector) Equals(key, value string) *Selector {
+	return s.add(key, "=", value)
+}
+
+// NotEquals requires key to be absent or have a value other than value.
+func (s *Selector) NotEquals(key, value string) *Selector {
+	return s.add(key, "!=", value)
+}
+
+// In requires key to have one of values.
+func (s *Selector) In(key string, values ...string) *Selector {
+	return s.add(key, "in", values...)
+}
+
+// NotIn requires key to be absent or have none of values.
+func (s *Selector) NotIn(key string, values ...string) *Selector {
+	return s.add(key, "notin", values...)
+}
+
+// Exists requires key to be present, with any value.
+func (s *Selector) Exists(key string) *Selector {
+	return s.add(key, "exists")
+}
+
+// DoesNotExist requires key to be absent.
+func (s *Selector) DoesNotExist(key string) *Selector {
+	return s.add(key, "!")
+}
+
+func (s *Selector) add(key, op string, values ...string) *Selector {
+	if err := validateRequirement(key, op, values); err != nil {
+		if s.err == nil {
+			s.err = err
+		}
+		return s
+	}
+	s.requirements = append(s.requirements, selectorRequirement{key: key, op: op, values: values})
+	return s
+}
+
+// Err returns the first invalid requirement added to the selector.
+func (s *Selector) Err() error {
+	return s.err
+}
+
+// Empty reports whether the selector has no requirements.
+func (s *Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// String renders the selector in the labelSelector query syntax.
+func (s *Selector) String() string {
+	parts := make([]string, len(s.requirements))
+	for i, r := range s.requirements {
+		switch r.op {
+		case "exists":
+			parts[i] = r.key
+		case "!":
+			parts[i] = "!" + r.key
+		case "in", "notin":
+			parts[i] = r.key + " " + r.op + " (" + strings.Join(r.values, ",") + ")"
+		default:
+			parts[i] = r.key + r.op + r.values[0]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseSelector parses and validates a selector in the labelSelector query
+// syntax, such as "app=web,tier in (backend,cache),!canary".
+func ParseSelector(selector string) (*Selector, error) {
+	s := NewSelector()
+	if strings.TrimSpace(selector) == "" {
+		return s, nil
+	}
+	for _, part := range splitSelector(selector) {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("invalid label selector %q: empty requirement", selector)
+		case setRequirementRE.MatchString(part):
+			m := setRequirementRE.FindStringSubmatch(part)
+			var values []string
+			for _, v := range strings.Split(m[3], ",") {
+				values = append(values, strings.TrimSpace(v))
+			}
+			s.add(m[1], m[2], values...)
+		case strings.HasPrefix(part, "!"):
+			s.add(strings.TrimSpace(part[1:]), "!")
+		case strings.Contains(part, "!="):
+			i := strings.Index(part, "!=")
+			s.add(strings.TrimSpace(part[:i]), "!=", strings.TrimSpace(part[i+2:]))
+		case strings.Contains(part, "=="):
+			i := strings.Index(part, "==")
+			s.add(strings.TrimSpace(part[:i]), "=", strings.TrimSpace(part[i+2:]))
+		case strings.Contains(part, "="):
+			i := strings.Index(part, "=")
+			s.add(strings.TrimSpace(part[:i]), "=", strings.TrimSpace(part[i+1:]))
+		default:
+			s.add(part, "exists")
+		}
+		if s.err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %v", selector, s.err)
+		}
+	}
+	return s, nil
+}
+
+// splitSelector splits a selector at the commas between requirements,
+// leaving those inside set requirements' parentheses alone.
+func splitSelector(selector string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, selector[start:])
+}
+
+func validateRequirement(key, op string, values []string) error {
+	if err := validateLabelKey(key); err != nil {
+		return err
+	}
+	switch op {
+	case "in", "notin":
+		if len(values) == 0 {
+			return fmt.Errorf("%s requirement on %q needs at least one value", op, key)
+		}
+	case "=", "!=":
+		if len(values) != 1 {
+			return fmt.Errorf("%s requirement on %q needs exactly one value", op, key)
+		}
+	}
+	for _, v := range values {
+		if err := validateLabelValue(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLabelKey(key string) error {
+	name := key
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if len(prefix) == 0 || len(prefix) > 253 || !labelPrefixRE.MatchString(prefix) {
+			return fmt.Errorf("invalid label key %q: prefix must be a DNS subdomain", key)
+		}
+	}
+	if name == "" {
+		return errors.New("empty label key")
+	}
+	if len(name) > 63 || !labelNameRE.MatchString(name) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+func validateLabelValue(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > 63 || !labelNameRE.MatchString(value) {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+	return nil
+}
+
+// WithSelector returns kubeResource with its label selector replaced by
+// sel, for use with ListKubeResources, Watch, and the other generic
+// resource functions. The selector's own errors are reported when it is
+// used.
+func WithSelector(kubeResource KubeResource, sel *Selector) KubeResource {
+	return &selectedResource{KubeResource: kubeResource, sel: sel}
+}
+
+type selectedResource struct {
+	KubeResource
+	sel *Selector
+}
+
+func (r *selectedResource) KubeResourceLabel() string {
+	return r.sel.String()
+}
+
+// resourceSelector returns the validated label selector of kubeResource.
+func resourceSelector(kubeResource KubeResource) (string, error) {
+	if r, ok := kubeResource.(*selectedResource); ok && r.sel.Err() != nil {
+		return "", fmt.Errorf("invalid label selector: %v", r.sel.Err())
+	}
+	label := kubeResource.KubeResourceLabel()
+	if _, err := ParseSelector(label); err != nil {
+		return "", err
+	}
+	return label, nil
+}
@@ -0,0 +1,145 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	serviceAccountsPath = apiPrefix + "/namespaces/%s/serviceaccounts"
+	serviceAccountPath  = apiPrefix + "/namespaces/%s/serviceaccounts/%s"
+
+	// serviceAccountNameAnnotation names the service account a token
+	// secret belongs to.
+	serviceAccountNameAnnotation = "kubernetes.io/service-account.name"
+)
+
+type ServiceAccountResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (sa *ServiceAccountResource) KubeResourcesURL() string {
+	return sa.Host + fmt.Sprintf(serviceAccountsPath, sa.Namespace)
+}
+
+func (sa *ServiceAccountResource) KubeResourceNamespace() string {
+	return sa.Namespace
+}
+
+func (sa *ServiceAccountResource) KubeResourceLabel() string {
+	return sa.Label
+}
+
+func (c *Client) CreateServiceAccount(ctx context.Context, account *api.ServiceAccount) (*api.ServiceAccount, error) {
+	var accountJSON bytes.Buffer
+	if err := json.NewEncoder(&accountJSON).Encode(account); err != nil {
+		return nil, fmt.Errorf("failed to encode service account in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", account.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &ServiceAccountResource{c.Host, namespace, ""}, accountJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.serviceAccountURL(namespace, account.Name), account)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var accountResult api.ServiceAccount
+	if err := json.Unmarshal(apiResult, &accountResult); err != nil {
+		return nil, fmt.Errorf("failed to decode service account resources: %v", err)
+	}
+	return &accountResult, nil
+}
+
+func (c *Client) GetServiceAccount(ctx context.Context, namespace, name string) (*api.ServiceAccount, error) {
+	apiResult, err := GetKubeResource(ctx, c.serviceAccountURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var account api.ServiceAccount
+	if err := json.Unmarshal(apiResult, &account); err != nil {
+		return nil, fmt.Errorf("failed to decode service account json: %v", err)
+	}
+	return &account, nil
+}
+
+// DeleteServiceAccount deletes the service account. Its token secrets are
+// deleted along with it.
+func (c *Client) DeleteServiceAccount(ctx context.Context, namespace, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.serviceAccountURL(namespace, name), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) ServiceAccountList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.ServiceAccount, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &ServiceAccountResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var accountList api.ServiceAccountList
+	if err := json.Unmarshal(apiResult, &accountList); err != nil {
+		return nil, fmt.Errorf("failed to decode service account resources: %v", err)
+	}
+	return accountList.Items, nil
+}
+
+// ServiceAccountTokenSecret gets the token secret of the service account,
+// whose "token" key holds the account's bearer token. Clusters before 1.24
+// create one for every account and list it in the account's Secrets; newer
+// ones only have one if it was created by hand, as a secret of type
+// api.SecretTypeServiceAccountToken annotated with the account's name, so
+// the namespace's secrets are searched for it too. The token itself is
+// filled in by the cluster shortly after such a secret is created, so it
+// may still be missing. ServiceAccountTokenSecret fails with an error
+// IsNotFound matches if the account has no token secret.
+func (c *Client) ServiceAccountTokenSecret(ctx context.Context, namespace, name string) (*api.Secret, error) {
+	account, err := c.GetServiceAccount(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range account.Secrets {
+		secret, err := c.GetSecret(ctx, namespace, ref.Name)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isServiceAccountToken(secret, account) {
+			return secret, nil
+		}
+	}
+
+	secrets, err := c.SecretList(ctx, namespace, "", ListOptions{FieldSelector: "type=" + string(api.SecretTypeServiceAccountToken)})
+	if err != nil {
+		return nil, err
+	}
+	for i := range secrets {
+		if isServiceAccountToken(&secrets[i], account) {
+			return &secrets[i], nil
+		}
+	}
+	return nil, &StatusError{
+		Code:    http.StatusNotFound,
+		Reason:  "NotFound",
+		Message: fmt.Sprintf("service account %s/%s has no token secret", namespace, name),
+		Method:  "GET",
+		URL:     c.serviceAccountURL(namespace, name),
+	}
+}
+
+// isServiceAccountToken reports whether secret is a token secret of
+// account.
+func isServiceAccountToken(secret *api.Secret, account *api.ServiceAccount) bool {
+	return secret.Type == api.SecretTypeServiceAccountToken &&
+		secret.Annotations[serviceAccountNameAnnotation] == account.Name
+}
+
+func (c *Client) serviceAccountURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(serviceAccountPath, namespace, name)
+}
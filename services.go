@@ -0,0 +1,92 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	servicesPath = apiPrefix + "/namespaces/%s/services"
+	servicePath  = apiPrefix + "/namespaces/%s/services/%s"
+)
+
+func (c *Client) CreateService(ctx context.Context, service *api.Service) (*api.Service, error) {
+	var serviceJSON bytes.Buffer
+	if err := json.NewEncoder(&serviceJSON).Encode(service); err != nil {
+		return nil, fmt.Errorf("failed to encode service in json: %v", err)
+	}
+
+	namespace, err := c.resolveNamespace("", service.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &ServiceResource{c.Host, namespace, ""}, serviceJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.serviceURL(namespace, service.Name), service)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+
+	var serviceResult api.Service
+	if err := json.Unmarshal(apiResult, &serviceResult); err != nil {
+		return nil, fmt.Errorf("failed to decode service resources: %v", err)
+	}
+	return &serviceResult, nil
+}
+
+func (c *Client) GetService(ctx context.Context, namespace, name string) (*api.Service, error) {
+	apiResult, err := GetKubeResource(ctx, c.serviceURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var service api.Service
+	if err := json.Unmarshal(apiResult, &service); err != nil {
+		return nil, fmt.Errorf("failed to decode service json: %v", err)
+	}
+	return &service, nil
+}
+
+func (c *Client) DeleteService(ctx context.Context, namespace, serviceName string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.serviceURL(namespace, serviceName), firstDeleteOptions(opts), c.Client)
+}
+
+func (c *Client) ServiceList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]api.Service, error) {
+	var services []api.Service
+
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &ServiceResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return services, fmt.Errorf("Resource List failed: %w", err)
+	}
+
+	var serviceList api.ServiceList
+	if err := json.Unmarshal(apiResult, &serviceList); err != nil {
+		return services, fmt.Errorf("failed to decode service resources: %v", err)
+	}
+
+	return serviceList.Items, nil
+}
+
+func (c *Client) serviceURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(servicePath, namespace, name)
+}
+
+type ServiceResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (s *ServiceResource) KubeResourcesURL() string {
+	return s.Host + fmt.Sprintf(servicesPath, s.Namespace)
+}
+
+func (s *ServiceResource) KubeResourceNamespace() string {
+	return s.Namespace
+}
+
+func (s *ServiceResource) KubeResourceLabel() string {
+	return s.Label
+}
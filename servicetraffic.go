@@ -0,0 +1,129 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/wearemolecule/kubeclient/labels"
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// Traffic policies for ServiceTraffic.ExternalTrafficPolicy and
+// InternalTrafficPolicy.
+const (
+	// TrafficPolicyCluster routes to ready endpoints on any node.
+	TrafficPolicyCluster = "Cluster"
+	// TrafficPolicyLocal routes only to endpoints on the node that
+	// received the traffic, preserving client source IPs for external
+	// traffic and saving a hop.
+	TrafficPolicyLocal = "Local"
+)
+
+// Topology modes for ServiceTraffic.TopologyMode.
+const (
+	// TopologyModeAuto makes kube-proxy prefer endpoints in the client's
+	// zone, where the EndpointSlice controller judges it safe.
+	TopologyModeAuto = "Auto"
+	// TopologyModeDisabled routes to endpoints in any zone.
+	TopologyModeDisabled = "Disabled"
+)
+
+// ServiceTraffic is how traffic to a service is routed to its endpoints.
+// Zero fields are left unchanged by ConfigureServiceTraffic.
+type ServiceTraffic struct {
+	// SessionAffinity is api.ServiceAffinityClientIP to send each client
+	// to the same endpoint, or api.ServiceAffinityNone.
+	SessionAffinity api.ServiceAffinity
+	// SessionAffinityTimeout is how long a ClientIP affinity lasts after a
+	// client's last request, rounded down to whole seconds. Kubernetes
+	// defaults to three hours.
+	SessionAffinityTimeout time.Duration
+	// ExternalTrafficPolicy applies to NodePort and LoadBalancer traffic.
+	ExternalTrafficPolicy string
+	// InternalTrafficPolicy applies to traffic to the cluster IP.
+	InternalTrafficPolicy string
+	// TopologyMode is TopologyModeAuto or TopologyModeDisabled.
+	TopologyMode string
+}
+
+// trafficService is the part of a service that ServiceTraffic covers; the
+// api package predates most of it.
+type trafficService struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		SessionAffinity       api.ServiceAffinity `json:"sessionAffinity"`
+		SessionAffinityConfig *struct {
+			ClientIP *struct {
+				TimeoutSeconds int `json:"timeoutSeconds"`
+			} `json:"clientIP"`
+		} `json:"sessionAffinityConfig"`
+		ExternalTrafficPolicy string `json:"externalTrafficPolicy"`
+		InternalTrafficPolicy string `json:"internalTrafficPolicy"`
+	} `json:"spec"`
+}
+
+// GetServiceTraffic returns the traffic settings of the service.
+func (c *Client) GetServiceTraffic(ctx context.Context, namespace, name string) (*ServiceTraffic, error) {
+	apiResult, err := GetKubeResource(ctx, c.serviceURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var service trafficService
+	if err := json.Unmarshal(apiResult, &service); err != nil {
+		return nil, fmt.Errorf("failed to decode service json: %v", err)
+	}
+	traffic := &ServiceTraffic{
+		SessionAffinity:       service.Spec.SessionAffinity,
+		ExternalTrafficPolicy: service.Spec.ExternalTrafficPolicy,
+		InternalTrafficPolicy: service.Spec.InternalTrafficPolicy,
+		TopologyMode:          service.Metadata.Annotations[labels.TopologyMode],
+	}
+	if config := service.Spec.SessionAffinityConfig; config != nil && config.ClientIP != nil {
+		traffic.SessionAffinityTimeout = time.Duration(config.ClientIP.TimeoutSeconds) * time.Second
+	}
+	return traffic, nil
+}
+
+// ConfigureServiceTraffic applies the non-zero fields of traffic to the
+// service and returns the updated service. ExternalTrafficPolicy may only
+// be set on NodePort and LoadBalancer services, and SessionAffinityTimeout
+// only with ClientIP affinity; the apiserver rejects other combinations.
+func (c *Client) ConfigureServiceTraffic(ctx context.Context, namespace, name string, traffic ServiceTraffic) (*api.Service, error) {
+	spec := map[string]interface{}{}
+	if traffic.SessionAffinity != "" {
+		spec["sessionAffinity"] = traffic.SessionAffinity
+	}
+	if traffic.SessionAffinityTimeout > 0 {
+		spec["sessionAffinityConfig"] = map[string]interface{}{
+			"clientIP": map[string]interface{}{
+				"timeoutSeconds": int(traffic.SessionAffinityTimeout / time.Second),
+			},
+		}
+	}
+	if traffic.ExternalTrafficPolicy != "" {
+		spec["externalTrafficPolicy"] = traffic.ExternalTrafficPolicy
+	}
+	if traffic.InternalTrafficPolicy != "" {
+		spec["internalTrafficPolicy"] = traffic.InternalTrafficPolicy
+	}
+	patch := map[string]interface{}{"spec": spec}
+	if traffic.TopologyMode != "" {
+		patch["metadata"] = map[string]interface{}{
+			"annotations": map[string]string{labels.TopologyMode: traffic.TopologyMode},
+		}
+	}
+
+	apiResult, err := mergePatchKubeResource(ctx, c.serviceURL(namespace, name), patch, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Update failed: %w", err)
+	}
+	var service api.Service
+	if err := json.Unmarshal(apiResult, &service); err != nil {
+		return nil, fmt.Errorf("failed to decode service json: %v", err)
+	}
+	return &service, nil
+}
@@ -0,0 +1,100 @@
+package kubeclient
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// AwaitStable watches the objects in namespace matching selector, of the
+// kinds Inventory covers, and returns once none of them has been added,
+// changed, or deleted for quietPeriod, such as after a deploy, when its
+// pods have started and become ready. The quiet period starts once every
+// kind has been listed. Status changes count, so a pod crash-looping keeps
+// it waiting. Kinds the caller may not list (403 Forbidden), or the cluster
+// does not serve (404 Not Found), are left out. A nil selector watches
+// every object in namespace. It fails if a watch fails or ctx is done
+// first.
+func (c *Client) AwaitStable(ctx context.Context, namespace string, selector *Selector, quietPeriod time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// stableEvent is sent for every change seen, with listed set instead
+	// for a kind's initial list, or with the error that ended a watch.
+	type stableEvent struct {
+		listed bool
+		err    error
+	}
+	changes := make(chan stableEvent)
+	for name := range inventoryResources {
+		events, err := c.WatchResumable(ctx, WithSelector(inventoryResource(name, c.Host, namespace), selector), "")
+		if err != nil {
+			return err
+		}
+		go func(name string, events <-chan WatchEvent) {
+			listed := false
+			for ev := range events {
+				var change stableEvent
+				switch {
+				case ev.Err != nil:
+					if code := statusCode(ev.Err); code == http.StatusForbidden || code == http.StatusNotFound {
+						// Leave the kind out, counting it as listed.
+						if listed {
+							return
+						}
+						listed = true
+						change.listed = true
+					} else {
+						change.err = fmt.Errorf("watch of %s failed: %w", name, ev.Err)
+					}
+				case ev.Type == WatchBookmark:
+					continue
+				case ev.Type == WatchResyncNeeded && !listed:
+					// The initial list, not a change.
+					listed = true
+					change.listed = true
+				}
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(name, events)
+	}
+
+	clock := c.clock()
+	unlisted := len(inventoryResources)
+	var quiet Timer
+	var quietC <-chan time.Time
+	defer func() {
+		if quiet != nil {
+			quiet.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("objects did not settle: %v", ctx.Err())
+		case change := <-changes:
+			if change.err != nil {
+				return change.err
+			}
+			if change.listed {
+				unlisted--
+			}
+			if unlisted > 0 {
+				continue
+			}
+			if quiet != nil {
+				quiet.Stop()
+			}
+			quiet = clock.NewTimer(quietPeriod)
+			quietC = quiet.C()
+		case <-quietC:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	statefulSetsPath = appsPrefix + "/namespaces/%s/statefulsets"
+	statefulSetPath  = appsPrefix + "/namespaces/%s/statefulsets/%s"
+
+	// defaultClusterDomain is the DNS domain of a cluster that was not
+	// given another one.
+	defaultClusterDomain = "cluster.local"
+)
+
+// StatefulSet is an apps/v1 StatefulSet, which the api package predates.
+type StatefulSet struct {
+	api.TypeMeta   `json:",inline"`
+	api.ObjectMeta `json:"metadata,omitempty"`
+	Spec           StatefulSetSpec   `json:"spec,omitempty"`
+	Status         StatefulSetStatus `json:"status,omitempty"`
+}
+
+type StatefulSetSpec struct {
+	Replicas *int           `json:"replicas,omitempty"`
+	Selector *LabelSelector `json:"selector"`
+	// ServiceName is the headless service that gives the set's pods their
+	// DNS names.
+	ServiceName         string              `json:"serviceName"`
+	Template            api.PodTemplateSpec `json:"template"`
+	PodManagementPolicy string              `json:"podManagementPolicy,omitempty"`
+	// VolumeClaimTemplates are the claims each pod gets its own copy of,
+	// named "<template>-<set>-<ordinal>". They outlive the set's pods.
+	VolumeClaimTemplates []PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
+	UpdateStrategy       *StatefulSetStrategy    `json:"updateStrategy,omitempty"`
+}
+
+type StatefulSetStrategy struct {
+	// Type is "RollingUpdate", the default, or "OnDelete".
+	Type          string                            `json:"type,omitempty"`
+	RollingUpdate *RollingUpdateStatefulSetStrategy `json:"rollingUpdate,omitempty"`
+}
+
+type RollingUpdateStatefulSetStrategy struct {
+	// Partition holds back the pods with lower ordinals from an update,
+	// for staged rollouts.
+	Partition *int `json:"partition,omitempty"`
+}
+
+type StatefulSetStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	Replicas           int   `json:"replicas"`
+	ReadyReplicas      int   `json:"readyReplicas,omitempty"`
+	CurrentReplicas    int   `json:"currentReplicas,omitempty"`
+	UpdatedReplicas    int   `json:"updatedReplicas,omitempty"`
+	// CurrentRevision and UpdateRevision name the revisions of the pod
+	// template the set's pods are at and are being updated to; they match
+	// once a rollout is done.
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	UpdateRevision  string `json:"updateRevision,omitempty"`
+}
+
+type StatefulSetResource struct {
+	Host      string
+	Namespace string
+	Label     string
+}
+
+func (s *StatefulSetResource) KubeResourcesURL() string {
+	return s.Host + fmt.Sprintf(statefulSetsPath, s.Namespace)
+}
+
+func (s *StatefulSetResource) KubeResourceNamespace() string {
+	return s.Namespace
+}
+
+func (s *StatefulSetResource) KubeResourceLabel() string {
+	return s.Label
+}
+
+func (c *Client) CreateStatefulSet(ctx context.Context, set *StatefulSet) (*StatefulSet, error) {
+	var setJSON bytes.Buffer
+	if err := json.NewEncoder(&setJSON).Encode(set); err != nil {
+		return nil, fmt.Errorf("failed to encode statefulset in json: %v", err)
+	}
+	namespace, err := c.resolveNamespace("", set.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	apiResult, err := CreateKubeResource(ctx, &StatefulSetResource{c.Host, namespace, ""}, setJSON, c.Client)
+	if err != nil {
+		err = c.conflictError(ctx, err, c.statefulSetURL(namespace, set.Name), set)
+		return nil, fmt.Errorf("Create failed: %w", err)
+	}
+	var setResult StatefulSet
+	if err := json.Unmarshal(apiResult, &setResult); err != nil {
+		return nil, fmt.Errorf("failed to decode statefulset resources: %v", err)
+	}
+	return &setResult, nil
+}
+
+func (c *Client) GetStatefulSet(ctx context.Context, namespace, name string) (*StatefulSet, error) {
+	apiResult, err := GetKubeResource(ctx, c.statefulSetURL(namespace, name), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var set StatefulSet
+	if err := json.Unmarshal(apiResult, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode statefulset json: %v", err)
+	}
+	return &set, nil
+}
+
+func (c *Client) StatefulSetList(ctx context.Context, namespace, label string, opts ...ListOptions) ([]StatefulSet, error) {
+	apiResult, err := ListKubeResourcesWithOptions(ctx, &StatefulSetResource{c.Host, namespace, label}, firstListOptions(opts), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var setList struct {
+		Items []StatefulSet `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &setList); err != nil {
+		return nil, fmt.Errorf("failed to decode statefulset resources: %v", err)
+	}
+	return setList.Items, nil
+}
+
+// PatchStatefulSet applies patch, in the format given by patchType, to the
+// statefulset and returns the patched statefulset.
+func (c *Client) PatchStatefulSet(ctx context.Context, namespace, name string, patchType PatchType, patch []byte) (*StatefulSet, error) {
+	body, err := PatchKubeResource(ctx, c.statefulSetURL(namespace, name), patchType, patch, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Patch failed: %w", err)
+	}
+	var set StatefulSet
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode statefulset json: %v", err)
+	}
+	return &set, nil
+}
+
+// DeleteStatefulSet deletes the statefulset. The claims its pods were given
+// from its VolumeClaimTemplates are kept.
+func (c *Client) DeleteStatefulSet(ctx context.Context, namespace, name string, opts ...DeleteOptions) error {
+	return deleteKubeResource(ctx, c.statefulSetURL(namespace, name), firstDeleteOptions(opts), c.Client)
+}
+
+// ScaleStatefulSet sets the number of replicas of the statefulset through
+// its scale subresource. Pods are added in ordinal order and removed in
+// reverse, one at a time unless its PodManagementPolicy is "Parallel".
+func (c *Client) ScaleStatefulSet(ctx context.Context, namespace, name string, replicas int) error {
+	_, err := c.Scale(ctx, &StatefulSetResource{c.Host, namespace, ""}, name, replicas)
+	return err
+}
+
+// StatefulSetReady reports whether the statefulset controller has acted on
+// the latest spec, every desired replica is ready, and any rolling update
+// has reached every pod.
+func StatefulSetReady(set *StatefulSet) bool {
+	replicas := 1
+	if set.Spec.Replicas != nil {
+		replicas = *set.Spec.Replicas
+	}
+	if set.Status.ObservedGeneration < set.Generation ||
+		set.Status.ReadyReplicas < replicas ||
+		set.Status.Replicas != replicas {
+		return false
+	}
+	if s := set.Spec.UpdateStrategy; s != nil && s.Type == "OnDelete" {
+		// Pods only change when deleted by hand.
+		return true
+	}
+	if s := set.Spec.UpdateStrategy; s != nil && s.RollingUpdate != nil && s.RollingUpdate.Partition != nil {
+		// A staged rollout only updates the pods at or above the partition.
+		return set.Status.UpdatedReplicas >= replicas-*s.RollingUpdate.Partition
+	}
+	return set.Status.UpdatedReplicas >= replicas && set.Status.CurrentRevision == set.Status.UpdateRevision
+}
+
+// AwaitStatefulSetReady watches the statefulset until StatefulSetReady
+// holds, and returns it. Since the controller replaces pods one ordinal at
+// a time, this waits out a whole rollout, which may take a while for sets
+// of slow-starting pods such as databases. It fails if the statefulset is
+// deleted or ctx is done first.
+func (c *Client) AwaitStatefulSetReady(ctx context.Context, namespace, name string) (*StatefulSet, error) {
+	values := url.Values{}
+	values.Set("fieldSelector", "metadata.name="+name)
+	backoff := watchBackoff{clock: c.clock()}
+	for {
+		set, err := c.GetStatefulSet(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if StatefulSetReady(set) {
+			return set, nil
+		}
+		var ready *StatefulSet
+		err = watchKubeResourcesQuery(ctx, &StatefulSetResource{c.Host, namespace, ""}, values, set.ResourceVersion, c.Client, func(eventType string, object json.RawMessage) error {
+			var watched StatefulSet
+			if err := json.Unmarshal(object, &watched); err != nil {
+				return fmt.Errorf("failed to decode watched statefulset: %v", err)
+			}
+			backoff.reset()
+			if eventType == "DELETED" {
+				return fmt.Errorf("statefulset %s was deleted before it was ready", name)
+			}
+			if StatefulSetReady(&watched) {
+				ready = &watched
+				return errWatchDone
+			}
+			return nil
+		})
+		if ready != nil {
+			return ready, nil
+		}
+		if err != ErrWatchGone && err != ErrWatchClosed {
+			return nil, err
+		}
+		if err := backoff.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// StatefulSetPeer is a member of a StatefulSet, as seen by its peers.
+type StatefulSetPeer struct {
+	Ordinal int
+	// DNSName is the pod's stable name in the set's headless service,
+	// e.g. "db-0.db.prod.svc.cluster.local".
+	DNSName string
+	// Ready is true if the pod exists and its Ready condition is True.
+	Ready bool
+}
+
+// StatefulSetPeers returns a peer for each ordinal the set's spec asks for,
+// in ordinal order, with its DNS name in service and whether its pod in
+// pods is ready. service must be the set's headless service. An empty
+// clusterDomain means "cluster.local".
+func StatefulSetPeers(set *StatefulSet, service *api.Service, pods []api.Pod, clusterDomain string) ([]StatefulSetPeer, error) {
+	if service.Name != set.Spec.ServiceName {
+		return nil, fmt.Errorf("statefulset %s is governed by service %q, not %q", set.Name, set.Spec.ServiceName, service.Name)
+	}
+	if service.Spec.ClusterIP != "None" {
+		return nil, fmt.Errorf("service %s is not headless", service.Name)
+	}
+	if clusterDomain == "" {
+		clusterDomain = defaultClusterDomain
+	}
+	replicas := 1
+	if set.Spec.Replicas != nil {
+		replicas = *set.Spec.Replicas
+	}
+
+	ready := map[int]bool{}
+	for i := range pods {
+		ordinal, ok := statefulSetOrdinal(set.Name, pods[i].Name)
+		if !ok {
+			continue
+		}
+		cond := GetPodCondition(&pods[i], api.PodReady)
+		ready[ordinal] = cond != nil && cond.Status == api.ConditionTrue
+	}
+
+	peers := make([]StatefulSetPeer, replicas)
+	for i := range peers {
+		peers[i] = StatefulSetPeer{
+			Ordinal: i,
+			DNSName: fmt.Sprintf("%s-%d.%s.%s.svc.%s", set.Name, i, service.Name, set.Namespace, clusterDomain),
+			Ready:   ready[i],
+		}
+	}
+	return peers, nil
+}
+
+// ReadyOrdinals returns the ordinals of the ready peers, in order.
+func ReadyOrdinals(peers []StatefulSetPeer) []int {
+	var ordinals []int
+	for _, p := range peers {
+		if p.Ready {
+			ordinals = append(ordinals, p.Ordinal)
+		}
+	}
+	sort.Ints(ordinals)
+	return ordinals
+}
+
+// GetStatefulSetPeers fetches the named StatefulSet, its headless service,
+// and its pods, and returns its peers as StatefulSetPeers does.
+func (c *Client) GetStatefulSetPeers(ctx context.Context, namespace, name, clusterDomain string) ([]StatefulSetPeer, error) {
+	set, err := c.GetStatefulSet(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	service, err := c.GetService(ctx, namespace, set.Spec.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := c.PodList(ctx, namespace, set.Spec.Selector.String())
+	if err != nil {
+		return nil, err
+	}
+	return StatefulSetPeers(set, service, pods, clusterDomain)
+}
+
+// statefulSetOrdinal returns the ordinal of a pod of the named set, whose
+// pods are named "<set>-<ordinal>".
+func statefulSetOrdinal(setName, podName string) (int, bool) {
+	if !strings.HasPrefix(podName, setName+"-") {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(podName[len(setName)+1:])
+	if err != nil || ordinal < 0 {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+func (c *Client) statefulSetURL(namespace, name string) string {
+	return c.Host + fmt.Sprintf(statefulSetPath, namespace, name)
+}
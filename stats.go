@@ -0,0 +1,234 @@
+package kubeclient
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statsDecay is the weight given to each new sample in the exponentially
+// weighted moving average. 0.2 smooths over roughly the last ten requests.
+const statsDecay = 0.2
+
+// StatsKey identifies a class of requests by verb (GET, LIST, WATCH, POST,
+// PUT, PATCH, DELETE) and resource (pods, replicationcontrollers, ...).
+type StatsKey struct {
+	Verb     string
+	Resource string
+}
+
+// LatencyStats summarizes the latency of a class of requests. Latency is
+// measured until the response headers arrive, so for watches it reflects the
+// time taken to establish the stream.
+type LatencyStats struct {
+	Count  int64
+	Errors int64
+	EWMA   time.Duration
+	Last   time.Duration
+	Max    time.Duration
+}
+
+// Stats returns a snapshot of the rolling latency statistics for every verb
+// and resource the client has requested so far. It returns nil for clients
+// that were not built by this package's constructors.
+func (c *Client) Stats() map[StatsKey]LatencyStats {
+	if c.stats == nil {
+		return nil
+	}
+	return c.stats.snapshot()
+}
+
+// RequestCounts are cumulative counts of a class of requests.
+type RequestCounts struct {
+	// Requests counts every attempt sent, retries included.
+	Requests int64
+	// Retries counts the attempts that were retries under the client's
+	// RetryPolicy.
+	Retries int64
+	// Throttled counts the attempts the apiserver answered with 429 Too
+	// Many Requests.
+	Throttled int64
+	// Errors counts the attempts that failed to get a response or got a
+	// 5xx one.
+	Errors int64
+}
+
+// StatsSnapshot holds the request counts a client accumulated since it was
+// created or the counts were last reset.
+type StatsSnapshot struct {
+	Since  time.Time
+	Taken  time.Time
+	Counts map[StatsKey]RequestCounts
+}
+
+// Snapshot returns the cumulative request counts for every verb and
+// resource the client has requested since it was created or the counts
+// were last reset, for exporting to SLO reports. It returns nil for
+// clients that were not built by this package's constructors.
+func (c *Client) Snapshot() *StatsSnapshot {
+	if c.stats == nil {
+		return nil
+	}
+	return c.stats.countsSnapshot(false)
+}
+
+// SnapshotAndReset is Snapshot, but also resets the counts to zero, without
+// losing any request counted between the two, for reporting the counts of
+// each period on its own.
+func (c *Client) SnapshotAndReset() *StatsSnapshot {
+	if c.stats == nil {
+		return nil
+	}
+	return c.stats.countsSnapshot(true)
+}
+
+type requestStats struct {
+	mu     sync.Mutex
+	stats  map[StatsKey]*LatencyStats
+	since  time.Time
+	counts map[StatsKey]*RequestCounts
+}
+
+func newRequestStats() *requestStats {
+	return &requestStats{
+		stats:  make(map[StatsKey]*LatencyStats),
+		since:  time.Now(),
+		counts: make(map[StatsKey]*RequestCounts),
+	}
+}
+
+// count returns the counts for key, adding them if needed. s.mu must be
+// held.
+func (s *requestStats) count(key StatsKey) *RequestCounts {
+	rc, ok := s.counts[key]
+	if !ok {
+		rc = &RequestCounts{}
+		s.counts[key] = rc
+	}
+	return rc
+}
+
+// retried counts a retry of a request classified by key.
+func (s *requestStats) retried(key StatsKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count(key).Retries++
+}
+
+func (s *requestStats) observe(key StatsKey, d time.Duration, failed, throttled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rc := s.count(key)
+	rc.Requests++
+	if failed {
+		rc.Errors++
+	}
+	if throttled {
+		rc.Throttled++
+	}
+	ls, ok := s.stats[key]
+	if !ok {
+		ls = &LatencyStats{EWMA: d}
+		s.stats[key] = ls
+	}
+	ls.Count++
+	if failed {
+		ls.Errors++
+	}
+	ls.Last = d
+	ls.EWMA = time.Duration(statsDecay*float64(d) + (1-statsDecay)*float64(ls.EWMA))
+	if d > ls.Max {
+		ls.Max = d
+	}
+}
+
+func (s *requestStats) snapshot() map[StatsKey]LatencyStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := make(map[StatsKey]LatencyStats, len(s.stats))
+	for k, v := range s.stats {
+		snapshot[k] = *v
+	}
+	return snapshot
+}
+
+func (s *requestStats) countsSnapshot(reset bool) *StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := &StatsSnapshot{
+		Since:  s.since,
+		Taken:  time.Now(),
+		Counts: make(map[StatsKey]RequestCounts, len(s.counts)),
+	}
+	for k, v := range s.counts {
+		snapshot.Counts[k] = *v
+	}
+	if reset {
+		s.since = snapshot.Taken
+		s.counts = make(map[StatsKey]*RequestCounts)
+	}
+	return snapshot
+}
+
+// statsTransport records the latency of every request in a requestStats.
+type statsTransport struct {
+	stats *requestStats
+	rt    http.RoundTripper
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.rt.RoundTrip(req)
+	failed := err != nil || res.StatusCode >= http.StatusInternalServerError
+	throttled := err == nil && res.StatusCode == http.StatusTooManyRequests
+	t.stats.observe(requestKey(req), time.Since(start), failed, throttled)
+	return res, err
+}
+
+func (t *statsTransport) unwrap() http.RoundTripper {
+	return t.rt
+}
+
+// requestKey classifies req by verb and resource from its URL path, which
+// takes one of the forms
+//
+//	/api/v1[/watch][/namespaces/{namespace}]/{resource}[/{name}[/{subresource}]]
+//	/apis/{group}/{version}[/watch][/namespaces/{namespace}]/{resource}[/{name}[/{subresource}]]
+func requestKey(req *http.Request) StatsKey {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return StatsKey{Verb: req.Method, Resource: req.URL.Path}
+	}
+
+	watch := req.URL.Query().Get("watch") == "true"
+	if len(parts) > 0 && parts[0] == "watch" {
+		watch = true
+		parts = parts[1:]
+	}
+	// A bare /namespaces or /namespaces/{name} addresses namespaces
+	// themselves rather than a resource inside one.
+	if len(parts) > 2 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+
+	key := StatsKey{Verb: req.Method}
+	if len(parts) > 0 {
+		key.Resource = parts[0]
+	}
+	if len(parts) > 2 {
+		key.Resource += "/" + parts[2]
+	}
+	switch {
+	case watch:
+		key.Verb = "WATCH"
+	case req.Method == "GET" && len(parts) == 1:
+		key.Verb = "LIST"
+	}
+	return key
+}
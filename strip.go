@@ -0,0 +1,66 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// StripOptions selects the server-managed fields to remove from objects
+// that are read for storage or comparison rather than for updating, such as
+// snapshots and exports.
+type StripOptions struct {
+	// ManagedFields removes metadata.managedFields, the server-side apply
+	// field ownership records.
+	ManagedFields bool
+	// Status removes the status the object's controllers report.
+	Status bool
+	// ServerMetadata removes the metadata the apiserver sets: its
+	// creationTimestamp, resourceVersion, uid, generation, and so on. An
+	// object stripped of it can be created again, but no longer guards an
+	// update against concurrent changes.
+	ServerMetadata bool
+}
+
+// StripFields removes the fields opts selects from objJSON, an object or a
+// list of objects as returned by GetKubeResource or ListKubeResources. The
+// metadata of a list itself, which carries the resourceVersion to watch
+// from, is kept.
+func StripFields(objJSON []byte, opts StripOptions) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(objJSON))
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("failed to decode object json: %v", err)
+	}
+	opts.strip(obj)
+	return encodeCanonical(obj)
+}
+
+// strip removes the selected fields from obj, or from each item if obj is
+// a list.
+func (opts StripOptions) strip(obj map[string]interface{}) {
+	if items, ok := obj["items"].([]interface{}); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]interface{}); ok {
+				opts.strip(m)
+			}
+		}
+		return
+	}
+	if opts.Status {
+		delete(obj, "status")
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		return
+	}
+	if opts.ManagedFields {
+		delete(metadata, "managedFields")
+	}
+	if opts.ServerMetadata {
+		for _, f := range serverMetadataFields {
+			delete(metadata, f)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// Taint effects.
+const (
+	// TaintNoSchedule keeps pods that do not tolerate the taint from being
+	// scheduled onto the node.
+	TaintNoSchedule = "NoSchedule"
+	// TaintPreferNoSchedule makes the scheduler avoid the node for pods
+	// that do not tolerate the taint, without forbidding it.
+	TaintPreferNoSchedule = "PreferNoSchedule"
+	// TaintNoExecute also evicts running pods that do not tolerate the
+	// taint.
+	TaintNoExecute = "NoExecute"
+)
+
+// Taint is a node taint, which the api package predates.
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+}
+
+// Toleration is a pod toleration, which the api package predates.
+type Toleration struct {
+	Key string `json:"key,omitempty"`
+	// Operator is "Equal", the default, or "Exists", which matches any
+	// value.
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	// Effect is the taint effect tolerated; empty tolerates all effects.
+	Effect            string `json:"effect,omitempty"`
+	TolerationSeconds *int64 `json:"tolerationSeconds,omitempty"`
+}
+
+// ToleratesTaint reports whether toleration matches taint. A toleration
+// with an empty key and the Exists operator matches every taint.
+func ToleratesTaint(toleration Toleration, taint Taint) bool {
+	if toleration.Effect != "" && toleration.Effect != taint.Effect {
+		return false
+	}
+	if toleration.Key != "" && toleration.Key != taint.Key {
+		return false
+	}
+	switch toleration.Operator {
+	case "Exists":
+		return true
+	case "", "Equal":
+		return toleration.Key != "" && toleration.Value == taint.Value
+	}
+	return false
+}
+
+// Tolerates reports whether a pod with tolerations may be scheduled onto a
+// node with taints, as the scheduler decides it: every NoSchedule and
+// NoExecute taint must be tolerated, while PreferNoSchedule taints only
+// lower the node's score and are ignored.
+func Tolerates(tolerations []Toleration, taints []Taint) bool {
+	for _, taint := range taints {
+		if taint.Effect == TaintPreferNoSchedule {
+			continue
+		}
+		if !toleratesAny(tolerations, taint) {
+			return false
+		}
+	}
+	return true
+}
+
+// UntoleratedTaints returns the taints in taints, of any effect, that no
+// toleration matches, for explaining why a pod does not fit a node.
+func UntoleratedTaints(tolerations []Toleration, taints []Taint) []Taint {
+	var untolerated []Taint
+	for _, taint := range taints {
+		if !toleratesAny(tolerations, taint) {
+			untolerated = append(untolerated, taint)
+		}
+	}
+	return untolerated
+}
+
+func toleratesAny(tolerations []Toleration, taint Taint) bool {
+	for _, toleration := range tolerations {
+		if ToleratesTaint(toleration, taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// TolerableNodes returns the names of the nodes in nodeTaints, which maps
+// node names to their taints, that tolerations let a pod be scheduled
+// onto, sorted.
+func TolerableNodes(tolerations []Toleration, nodeTaints map[string][]Taint) []string {
+	var nodes []string
+	for node, taints := range nodeTaints {
+		if Tolerates(tolerations, taints) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// NodeTaints returns the taints of every node in the cluster matching
+// label, by node name.
+func (c *Client) NodeTaints(ctx context.Context, label string) (map[string][]Taint, error) {
+	resource := &APIResource{Host: c.Host, Version: "v1", Resource: "nodes", Label: label}
+	apiResult, err := ListKubeResources(ctx, resource, c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource List failed: %w", err)
+	}
+	var nodeList struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Taints []Taint `json:"taints"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(apiResult, &nodeList); err != nil {
+		return nil, fmt.Errorf("failed to decode node resources: %v", err)
+	}
+	nodeTaints := make(map[string][]Taint, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		nodeTaints[node.Metadata.Name] = node.Spec.Taints
+	}
+	return nodeTaints, nil
+}
+
+// PodTolerations returns the tolerations of the pod.
+func (c *Client) PodTolerations(ctx context.Context, namespace, podName string) ([]Toleration, error) {
+	apiResult, err := GetKubeResource(ctx, c.podURL(namespace, podName), c.Client)
+	if err != nil {
+		return nil, fmt.Errorf("Resource Get failed: %w", err)
+	}
+	var pod struct {
+		Spec struct {
+			Tolerations []Toleration `json:"tolerations"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(apiResult, &pod); err != nil {
+		return nil, fmt.Errorf("failed to decode pod json: %v", err)
+	}
+	return pod.Spec.Tolerations, nil
+}
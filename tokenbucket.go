@@ -0,0 +1,177 @@
+package kubeclient
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// TokenBucket is a RateLimiter that lets requests through at a steady QPS,
+// allowing bursts of up to Burst requests after a quiet period, like
+// client-go's default flow control. Requests held back are let through in
+// order of priority, and in arrival order within a priority. Use it with
+// UseRateLimiter:
+//
+//	c.UseRateLimiter(kubeclient.NewTokenBucket(20, 50))
+type TokenBucket struct {
+	qps   float64
+	burst int
+	// Clock, if set, replaces the real clock in refilling the bucket.
+	Clock Clock
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	waiters bucketWaiters
+	seq     uint64
+}
+
+// NewTokenBucket returns a full TokenBucket refilling at qps tokens a second
+// and holding at most burst tokens (at least 1). A qps that is not positive
+// disables the limit.
+func NewTokenBucket(qps float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{qps: qps, burst: burst}
+}
+
+func (b *TokenBucket) clock() Clock {
+	if b.Clock == nil {
+		return realClock{}
+	}
+	return b.Clock
+}
+
+// refill adds the tokens accrued since the last refill. b.mu must be held.
+func (b *TokenBucket) refill() {
+	now := b.clock().Now()
+	if b.last.IsZero() {
+		b.tokens = float64(b.burst)
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * b.qps
+		if b.tokens > float64(b.burst) {
+			b.tokens = float64(b.burst)
+		}
+	}
+	b.last = now
+}
+
+// wakeHead has the first waiter look at the bucket again. b.mu must be held.
+func (b *TokenBucket) wakeHead() {
+	if len(b.waiters) > 0 {
+		select {
+		case b.waiters[0].wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Wait takes a token from the bucket, blocking until one is available and
+// no request of a higher or equal priority that arrived earlier is waiting.
+func (b *TokenBucket) Wait(ctx context.Context, p Priority) error {
+	if b.qps <= 0 {
+		return ctx.Err()
+	}
+	b.mu.Lock()
+	b.refill()
+	if len(b.waiters) == 0 && b.tokens >= 1 {
+		b.tokens--
+		b.mu.Unlock()
+		return nil
+	}
+
+	var head *bucketWaiter
+	if len(b.waiters) > 0 {
+		head = b.waiters[0]
+	}
+	w := &bucketWaiter{priority: p, seq: b.seq, wake: make(chan struct{}, 1)}
+	b.seq++
+	heap.Push(&b.waiters, w)
+	if head != nil && b.waiters[0] != head {
+		// w displaces the old first waiter, which must stop waiting for
+		// the next token.
+		select {
+		case head.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	for {
+		var timer Timer
+		var fire <-chan time.Time
+		if b.waiters[0] == w {
+			b.refill()
+			if b.tokens >= 1 {
+				b.tokens--
+				heap.Remove(&b.waiters, w.index)
+				b.wakeHead()
+				b.mu.Unlock()
+				return nil
+			}
+			wait := time.Duration((1 - b.tokens) / b.qps * float64(time.Second))
+			timer = b.clock().NewTimer(wait)
+			fire = timer.C()
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			b.mu.Lock()
+			heap.Remove(&b.waiters, w.index)
+			b.wakeHead()
+			b.mu.Unlock()
+			return ctx.Err()
+		case <-fire:
+		case <-w.wake:
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+		b.mu.Lock()
+	}
+}
+
+type bucketWaiter struct {
+	priority Priority
+	seq      uint64
+	wake     chan struct{}
+	index    int
+}
+
+// bucketWaiters is a heap of waiters, highest priority and then earliest
+// first.
+type bucketWaiters []*bucketWaiter
+
+func (h bucketWaiters) Len() int { return len(h) }
+
+func (h bucketWaiters) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h bucketWaiters) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *bucketWaiters) Push(x interface{}) {
+	w := x.(*bucketWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *bucketWaiters) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return w
+}
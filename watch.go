@@ -0,0 +1,316 @@
+package kubeclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+const (
+	// WatchResyncNeeded is the type of the event a watch sends after its
+	// resourceVersion expired (HTTP 410 Gone) and it re-listed to resume.
+	// The event carries the current state of the watched object(s); any
+	// changes between the last event and the re-list were not observed.
+	WatchResyncNeeded = "RESYNC_NEEDED"
+
+	// WatchBookmark is the type of the events the apiserver sends to
+	// advance a watch's resourceVersion without any object changing. Only
+	// the object's metadata.resourceVersion is set.
+	WatchBookmark = "BOOKMARK"
+
+	watchBackoffInitial = 500 * time.Millisecond
+	watchBackoffMax     = 30 * time.Second
+)
+
+// ErrWatchGone ends a watch whose resourceVersion the apiserver no longer
+// has (410 Gone). The caller must re-list to learn the current state and
+// watch again from the list's resourceVersion.
+var ErrWatchGone = errors.New("watch resourceVersion is too old (410 Gone)")
+
+// ErrWatchClosed ends a watch that the apiserver closed, which it does after
+// a timeout. The caller may watch again from the last resourceVersion seen.
+var ErrWatchClosed = errors.New("watch closed by the apiserver")
+
+// errWatchDone is returned by watchKubeResources callbacks that have seen
+// what they were waiting for, to end the watch.
+var errWatchDone = errors.New("watch done")
+
+// watchStatusError converts the api.Status object of an ERROR watch event
+// into an error, mapping 410 Gone to ErrWatchGone.
+func watchStatusError(object json.RawMessage) error {
+	var status api.Status
+	if err := json.Unmarshal(object, &status); err != nil {
+		return fmt.Errorf("failed to decode watch error status: %v", err)
+	}
+	if status.Code == http.StatusGone {
+		return ErrWatchGone
+	}
+	return &StatusError{Code: status.Code, Reason: string(status.Reason), Message: status.Message, Method: "WATCH"}
+}
+
+// watchBackoff doubles the wait between re-lists that are not separated by
+// any successfully received event, so a watch that keeps expiring does not
+// hammer the apiserver with lists.
+type watchBackoff struct {
+	next  time.Duration
+	clock Clock
+}
+
+// reset is called whenever a watch receives an event.
+func (b *watchBackoff) reset() {
+	b.next = 0
+}
+
+// wait blocks for the current backoff, or until ctx is done.
+func (b *watchBackoff) wait(ctx context.Context) error {
+	if b.next == 0 {
+		b.next = watchBackoffInitial
+		return ctx.Err()
+	}
+	clock := b.clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	t := clock.NewTimer(b.next)
+	defer t.Stop()
+	b.next *= 2
+	if b.next > watchBackoffMax {
+		b.next = watchBackoffMax
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C():
+		return nil
+	}
+}
+
+// WatchEvent is an event received by Watch. Object is the raw JSON of the
+// object the event is about. Err is set on the last event sent before the
+// channel is closed.
+type WatchEvent struct {
+	Type   string
+	Object json.RawMessage
+	Err    error
+}
+
+// Watch watches the collection of resource, restricted to its label, for
+// changes after resourceVersion, sending each event on the returned channel
+// with its object left as raw JSON to decode. An empty resourceVersion
+// starts with synthetic ADDED events for the existing objects. The watch
+// runs over a single connection until ctx is done or the connection ends;
+// a last event then carries the error, ErrWatchClosed or ErrWatchGone if
+// the apiserver ended it, and the channel is closed.
+func (c *Client) Watch(ctx context.Context, resource KubeResource, resourceVersion string) (<-chan WatchEvent, error) {
+	return c.watch(ctx, resource, url.Values{}, resourceVersion)
+}
+
+// watch is Watch with extra query parameters, such as a fieldSelector.
+func (c *Client) watch(ctx context.Context, resource KubeResource, values url.Values, resourceVersion string) (<-chan WatchEvent, error) {
+	if _, err := url.Parse(resource.KubeResourcesURL()); err != nil {
+		return nil, err
+	}
+	if _, err := resourceSelector(resource); err != nil {
+		return nil, err
+	}
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		err := watchKubeResourcesQuery(ctx, resource, values, resourceVersion, c.Client, func(eventType string, object json.RawMessage) error {
+			select {
+			case events <- WatchEvent{Type: eventType, Object: object}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		select {
+		case events <- WatchEvent{Err: err}:
+		case <-ctx.Done():
+		}
+	}()
+	return events, nil
+}
+
+// WatchResumable is like Watch, but survives the apiserver closing the watch
+// and transient connection failures by watching again from the last
+// resourceVersion seen, so events are neither lost nor repeated. Bookmark
+// events are requested and passed on, so a quiet watch keeps an up to date
+// resourceVersion. If the resourceVersion has expired (410 Gone), or if
+// resourceVersion is empty, the collection is listed and sent as a single
+// WatchResyncNeeded event whose Object is the raw list, and the watch
+// continues from the list's resourceVersion. Other errors, such as 403
+// Forbidden, end the watch as for Watch, as does ctx being done.
+func (c *Client) WatchResumable(ctx context.Context, resource KubeResource, resourceVersion string) (<-chan WatchEvent, error) {
+	if _, err := url.Parse(resource.KubeResourcesURL()); err != nil {
+		return nil, err
+	}
+	if _, err := resourceSelector(resource); err != nil {
+		return nil, err
+	}
+	events := make(chan WatchEvent)
+	send := func(ev WatchEvent) error {
+		select {
+		case events <- ev:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	go func() {
+		defer close(events)
+		backoff := watchBackoff{clock: c.clock()}
+		relist := resourceVersion == ""
+		for {
+			if relist {
+				list, err := ListKubeResources(ctx, resource, c.Client)
+				if err != nil {
+					send(WatchEvent{Err: fmt.Errorf("Resource List failed: %w", err)})
+					return
+				}
+				resourceVersion = objectResourceVersion(list)
+				if send(WatchEvent{Type: WatchResyncNeeded, Object: list}) != nil {
+					return
+				}
+			}
+
+			values := url.Values{}
+			values.Set("allowWatchBookmarks", "true")
+			err := watchKubeResourcesQuery(ctx, resource, values, resourceVersion, c.Client, func(eventType string, object json.RawMessage) error {
+				backoff.reset()
+				if rv := objectResourceVersion(object); rv != "" {
+					resourceVersion = rv
+				}
+				return send(WatchEvent{Type: eventType, Object: object})
+			})
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err == ErrWatchClosed:
+				// Routine; watch again straight away.
+				relist = false
+				continue
+			case err == ErrWatchGone:
+				relist = true
+			case statusCode(err) != 0:
+				send(WatchEvent{Err: err})
+				return
+			default:
+				// A connection failure; retry from where we were.
+				relist = false
+			}
+			if backoff.wait(ctx) != nil {
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// objectResourceVersion returns the metadata.resourceVersion of a raw
+// object or list, or "" if it has none.
+func objectResourceVersion(object json.RawMessage) string {
+	var meta struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	json.Unmarshal(object, &meta)
+	return meta.Metadata.ResourceVersion
+}
+
+// closeOnDone closes body when ctx is done, unblocking readers of a
+// streaming response. The returned func stops the watcher.
+func closeOnDone(ctx context.Context, body io.Closer) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			body.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// watchKubeResources runs a single watch connection on the collection of
+// kubeResource, calling fn with the type and raw object of each event, until
+// the connection fails or fn returns an error. It returns ErrWatchGone if
+// resourceVersion has expired.
+func watchKubeResources(ctx context.Context, kubeResource KubeResource, resourceVersion string, httpClient *http.Client, fn func(eventType string, object json.RawMessage) error) error {
+	return watchKubeResourcesQuery(ctx, kubeResource, url.Values{}, resourceVersion, httpClient, fn)
+}
+
+// watchKubeResourcesQuery is watchKubeResources with extra query
+// parameters, such as a fieldSelector.
+func watchKubeResourcesQuery(ctx context.Context, kubeResource KubeResource, values url.Values, resourceVersion string, httpClient *http.Client, fn func(eventType string, object json.RawMessage) error) error {
+	watchURL, err := url.Parse(kubeResource.KubeResourcesURL())
+	if err != nil {
+		return err
+	}
+	label, err := resourceSelector(kubeResource)
+	if err != nil {
+		return err
+	}
+	values.Set("watch", "true")
+	if label != "" {
+		values.Set("labelSelector", label)
+	}
+	if resourceVersion != "" {
+		values.Set("resourceVersion", resourceVersion)
+	}
+	watchURL.RawQuery = values.Encode()
+
+	getURL := watchURL.String()
+	req, err := http.NewRequest("GET", getURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: GET %q : %v", getURL, err)
+	}
+	res, err := doRequest(ctx, httpClient, req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: GET %q: %v", getURL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusGone {
+		return ErrWatchGone
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return newStatusError("GET", getURL, res.StatusCode, body)
+	}
+
+	stop := closeOnDone(ctx, res.Body)
+	defer stop()
+
+	decoder := json.NewDecoder(res.Body)
+	for {
+		var event struct {
+			Type   string          `json:"type"`
+			Object json.RawMessage `json:"object"`
+		}
+		err := decoder.Decode(&event)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == io.EOF {
+			return ErrWatchClosed
+		}
+		if err != nil {
+			return fmt.Errorf("error reading streaming response body: %v", err)
+		}
+		if event.Type == "ERROR" {
+			return watchStatusError(event.Object)
+		}
+		if err := fn(event.Type, event.Object); err != nil {
+			return err
+		}
+	}
+}
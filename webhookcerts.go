@@ -0,0 +1,128 @@
+package kubeclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"golang.org/x/build/kubernetes/api"
+	"golang.org/x/net/context"
+)
+
+// ServingCert is a PEM encoded serving certificate and key for a webhook,
+// along with the CA that signed it, which is the caBundle the apiserver
+// needs to trust the webhook.
+type ServingCert struct {
+	CA   []byte
+	Cert []byte
+	Key  []byte
+}
+
+// GenerateServingCert creates a new CA and a certificate it signs for the
+// webhook served by the named service, valid for validFor.
+func GenerateServingCert(namespace, serviceName string, validFor time.Duration) (*ServingCert, error) {
+	now := time.Now()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %v", err)
+	}
+	caSerial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          caSerial,
+		Subject:               pkix.Name{CommonName: serviceName + "-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serving key: %v", err)
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	host := serviceName + "." + namespace + ".svc"
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{serviceName, serviceName + "." + namespace, host, host + ".cluster.local"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create serving certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode serving key: %v", err)
+	}
+
+	return &ServingCert{
+		CA:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %v", err)
+	}
+	return serial, nil
+}
+
+// Secret returns a kubernetes.io/tls secret holding the certificate, laid
+// out as cert-manager does, for the webhook's pods to mount.
+func (sc *ServingCert) Secret(namespace, name string) *api.Secret {
+	secret := &api.Secret{
+		Type: "kubernetes.io/tls",
+		Data: map[string][]byte{
+			"ca.crt":  sc.CA,
+			"tls.crt": sc.Cert,
+			"tls.key": sc.Key,
+		},
+	}
+	secret.Namespace = namespace
+	secret.Name = name
+	return secret
+}
+
+// ServingCertFromSecret reads a serving certificate from a
+// kubernetes.io/tls secret, such as one issued by cert-manager, which must
+// include the CA under "ca.crt".
+func (c *Client) ServingCertFromSecret(ctx context.Context, namespace, name string) (*ServingCert, error) {
+	secret, err := c.GetSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	sc := &ServingCert{
+		CA:   secret.Data["ca.crt"],
+		Cert: secret.Data["tls.crt"],
+		Key:  secret.Data["tls.key"],
+	}
+	if len(sc.CA) == 0 {
+		return nil, fmt.Errorf("secret %s/%s has no ca.crt", namespace, name)
+	}
+	return sc, nil
+}
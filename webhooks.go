@@ -0,0 +1,126 @@
+package kubeclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/net/context"
+)
+
+const admissionRegistrationGroup = "admissionregistration.k8s.io"
+
+// webhookConfigurationResources are the resources whose webhooks carry a
+// caBundle.
+var webhookConfigurationResources = []string{
+	"mutatingwebhookconfigurations",
+	"validatingwebhookconfigurations",
+}
+
+// RotateWebhookCABundle sets the caBundle of every webhook in the mutating
+// and validating webhook configurations matching label, and returns the
+// configurations it updated as "<resource>/<name>". Configurations are
+// decoded generically, so fields this package does not know about survive.
+//
+// Each configuration is updated atomically, guarded by its resourceVersion.
+// If any update fails, the configurations already updated are restored to
+// their previous caBundles, as far as possible, before the error is
+// returned, so that webhooks are not left trusting different CAs.
+func (c *Client) RotateWebhookCABundle(ctx context.Context, label string, caBundle []byte) ([]string, error) {
+	encoded := base64.StdEncoding.EncodeToString(caBundle)
+	type rotated struct {
+		resource *APIResource
+		name     string
+		previous []interface{}
+	}
+	var done []rotated
+	rollback := func(err error) ([]string, error) {
+		for _, r := range done {
+			previous := r.previous
+			restore := func(i int) interface{} {
+				if i < len(previous) {
+					return previous[i]
+				}
+				return nil
+			}
+			if _, rbErr := c.setCABundles(ctx, r.resource.ObjectURL(r.name), restore); rbErr != nil {
+				err = fmt.Errorf("%v; failed to restore caBundle of %s/%s: %v", err, r.resource.Resource, r.name, rbErr)
+			}
+		}
+		return nil, err
+	}
+
+	for _, resource := range webhookConfigurationResources {
+		kubeResource := &APIResource{c.Host, admissionRegistrationGroup, "v1", resource, "", label}
+		apiResult, err := ListKubeResources(ctx, kubeResource, c.Client)
+		if err != nil {
+			return rollback(fmt.Errorf("Resource List failed for %s: %w", resource, err))
+		}
+		var list itemNames
+		if err := json.Unmarshal(apiResult, &list); err != nil {
+			return rollback(fmt.Errorf("failed to decode %s resources: %v", resource, err))
+		}
+		for _, item := range list.Items {
+			name := item.Metadata.Name
+			previous, err := c.setCABundles(ctx, kubeResource.ObjectURL(name), func(int) interface{} { return encoded })
+			if err != nil {
+				return rollback(fmt.Errorf("failed to rotate caBundle of %s/%s: %w", resource, name, err))
+			}
+			done = append(done, rotated{kubeResource, name, previous})
+		}
+	}
+
+	names := make([]string, len(done))
+	for i, r := range done {
+		names[i] = r.resource.Resource + "/" + r.name
+	}
+	return names, nil
+}
+
+// setCABundles sets the caBundle of the i-th webhook of the configuration at
+// url to bundle(i), removing it if that is nil, and returns the caBundles it
+// replaced. It refetches the configuration and tries again if it changes
+// concurrently.
+func (c *Client) setCABundles(ctx context.Context, url string, bundle func(i int) interface{}) ([]interface{}, error) {
+	var replaced []interface{}
+	err := retryOnConflict(ctx, c.clock(), nil, func() error {
+		apiResult, err := GetKubeResource(ctx, url, c.Client)
+		if err != nil {
+			return fmt.Errorf("Resource Get failed: %w", err)
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal(apiResult, &config); err != nil {
+			return fmt.Errorf("failed to decode webhook configuration json: %v", err)
+		}
+		webhooks, _ := config["webhooks"].([]interface{})
+		replaced = make([]interface{}, len(webhooks))
+		for i, w := range webhooks {
+			webhook, _ := w.(map[string]interface{})
+			clientConfig, _ := webhook["clientConfig"].(map[string]interface{})
+			if clientConfig == nil {
+				continue
+			}
+			replaced[i] = clientConfig["caBundle"]
+			value := bundle(i)
+			if value == nil {
+				delete(clientConfig, "caBundle")
+			} else {
+				clientConfig["caBundle"] = value
+			}
+		}
+
+		var configJSON bytes.Buffer
+		if err := json.NewEncoder(&configJSON).Encode(config); err != nil {
+			return fmt.Errorf("failed to encode webhook configuration in json: %v", err)
+		}
+		if _, err := UpdateKubeResource(ctx, url, configJSON, c.Client); err != nil {
+			return fmt.Errorf("Update failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
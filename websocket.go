@@ -0,0 +1,201 @@
+package kubeclient
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// websocketGUID is the fixed key suffix of the WebSocket handshake
+// (RFC 6455, section 1.3).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes.
+const (
+	wsBinary = 0x2
+	wsClose  = 0x8
+	wsPing   = 0x9
+	wsPong   = 0xa
+)
+
+// maxWebSocketMessage is the largest message wsConn accepts.
+const maxWebSocketMessage = 16 * 1024 * 1024
+
+// wsConn is a minimal client side WebSocket connection, enough to speak the
+// apiserver's channel protocols for exec and attach.
+type wsConn struct {
+	rwc      io.ReadWriteCloser
+	protocol string
+
+	wmu sync.Mutex
+}
+
+// dialWebSocket upgrades a GET of url to a WebSocket connection offering
+// protocols, through httpClient so that the client's authentication and
+// other transport layers apply. A response other than 101 Switching
+// Protocols is returned as a *StatusError.
+func dialWebSocket(ctx context.Context, httpClient *http.Client, url string, protocols []string) (*wsConn, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: GET %q : %v", url, err)
+	}
+	var nonce [16]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
+
+	res, err := doRequest(ctx, httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: GET %q: %v", url, err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return nil, newStatusError("GET", url, res.StatusCode, body)
+	}
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if !ok {
+		res.Body.Close()
+		return nil, fmt.Errorf("websocket upgrade of %q returned a read-only connection", url)
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	if res.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		rwc.Close()
+		return nil, fmt.Errorf("websocket upgrade of %q returned an invalid accept key", url)
+	}
+	return &wsConn{rwc: rwc, protocol: res.Header.Get("Sec-WebSocket-Protocol")}, nil
+}
+
+// writeMessage sends data as a single binary message.
+func (c *wsConn) writeMessage(data []byte) error {
+	return c.writeFrame(wsBinary, data)
+}
+
+// writeFrame sends a single masked frame, as clients must.
+func (c *wsConn) writeFrame(opcode byte, data []byte) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | opcode
+	switch n := len(data); {
+	case n < 126:
+		header[1] = 0x80 | byte(n)
+	case n <= 0xffff:
+		header[1] = 0x80 | 126
+		header = header[:4]
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 0x80 | 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(rand.Reader, mask[:]); err != nil {
+		return err
+	}
+	header = append(header, mask[:]...)
+	frame := make([]byte, len(header)+len(data))
+	copy(frame, header)
+	for i, b := range data {
+		frame[len(header)+i] = b ^ mask[i%4]
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+// readMessage returns the next data message, answering pings and joining
+// fragments along the way. It returns io.EOF once the server closes the
+// connection.
+func (c *wsConn) readMessage() ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := c.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			c.writeFrame(wsClose, nil)
+			return nil, io.EOF
+		}
+		message = append(message, payload...)
+		if len(message) > maxWebSocketMessage {
+			return nil, errors.New("websocket message too large")
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.rwc, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	n := uint64(header[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rwc, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rwc, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxWebSocketMessage {
+		return false, 0, nil, errors.New("websocket message too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.rwc, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.rwc, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+func (c *wsConn) Close() error {
+	return c.rwc.Close()
+}
@@ -0,0 +1,125 @@
+package kubeclient
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	queueBackoffInitial = 5 * time.Millisecond
+	queueBackoffMax     = 5 * time.Minute
+)
+
+// workQueue is a queue of keys to process. A key is only queued once no
+// matter how often it is added, and a key added while it is being processed
+// is processed again once it is done, so no two workers ever hold the same
+// key.
+type workQueue struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	queue      []string
+	dirty      map[string]bool
+	processing map[string]bool
+	failures   map[string]int
+	shutdown   bool
+	clock      Clock
+}
+
+func newWorkQueue(clock Clock) *workQueue {
+	q := &workQueue{
+		clock:      clock,
+		dirty:      make(map[string]bool),
+		processing: make(map[string]bool),
+		failures:   make(map[string]int),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// add queues key unless it is already queued.
+func (q *workQueue) add(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.shutdown || q.dirty[key] {
+		return
+	}
+	q.dirty[key] = true
+	if q.processing[key] {
+		// done will queue it.
+		return
+	}
+	q.queue = append(q.queue, key)
+	q.cond.Signal()
+}
+
+// addAfter queues key once d has passed.
+func (q *workQueue) addAfter(key string, d time.Duration) {
+	if d <= 0 {
+		q.add(key)
+		return
+	}
+	t := q.clock.NewTimer(d)
+	go func() {
+		<-t.C()
+		q.add(key)
+	}()
+}
+
+// addRateLimited queues key after an exponential backoff that grows with
+// each failure recorded since the key was last forgotten.
+func (q *workQueue) addRateLimited(key string) {
+	q.mu.Lock()
+	n := q.failures[key]
+	q.failures[key] = n + 1
+	q.mu.Unlock()
+
+	backoff := queueBackoffInitial << uint(n)
+	if n > 30 || backoff > queueBackoffMax {
+		backoff = queueBackoffMax
+	}
+	q.addAfter(key, backoff)
+}
+
+// forget resets the failure count of key.
+func (q *workQueue) forget(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.failures, key)
+}
+
+// get blocks until a key is available and marks it as being processed. It
+// returns false once the queue is shut down.
+func (q *workQueue) get() (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.queue) == 0 && !q.shutdown {
+		q.cond.Wait()
+	}
+	if q.shutdown {
+		return "", false
+	}
+	key := q.queue[0]
+	q.queue = q.queue[1:]
+	q.processing[key] = true
+	delete(q.dirty, key)
+	return key, true
+}
+
+// done marks key as processed, queueing it again if it was added meanwhile.
+func (q *workQueue) done(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.processing, key)
+	if q.dirty[key] && !q.shutdown {
+		q.queue = append(q.queue, key)
+		q.cond.Signal()
+	}
+}
+
+// shutDown makes get return false to every waiting and future caller.
+func (q *workQueue) shutDown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.shutdown = true
+	q.cond.Broadcast()
+}